@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"github.com/MeTaNoV/gogrid/griddler"
+)
+
+// console reports the solving progress on the standard output
+type console struct{}
+
+func (console) Event(g *griddler.Griddler, e griddler.Event) {
+	switch e.Kind {
+	case griddler.PhaseStart:
+		switch e.Phase {
+		case griddler.PhaseLogic:
+			fmt.Printf("\nSolving\n")
+		case griddler.PhaseTrial:
+			fmt.Printf("Entering Trial&Error phase: %d / %d / %d\n", e.Selected, e.Potential, e.Total)
+		case griddler.PhaseSearch:
+			fmt.Printf("\nSearching solutions\n")
+		}
+	case griddler.TrialStart:
+		fmt.Printf("\rAttempt %3d / %3d", e.Attempt, e.Selected)
+	case griddler.TrialResult:
+		if e.Err != nil {
+			fmt.Printf("\nFOUND (%d,%d)\n", e.Line, e.Column)
+			g.Show()
+		}
+	case griddler.SolveEnd:
+		g.Show()
+		fmt.Printf("\nTotal trial attempts: %d/%d\n", e.Result.TrialsSuccess, e.Result.Trials)
+	}
+}
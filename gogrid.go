@@ -1,13 +1,32 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"github.com/MeTaNoV/gogrid/griddler"
+	"image"
 	"os"
+	"strings"
 )
 
-var fileName string
+var (
+	fileName string
+	verbose  bool
+	count    int
+	svgFile  string
+	pngFile  string
+	export   string
+	state    string
+	repair   string
+	generate string
+	density  float64
+	seed     int64
+	rule     string
+	rate     bool
+	hint     bool
+	opts     griddler.SolverOptions
+)
 
 func initFlags() {
 	const (
@@ -15,10 +34,63 @@ func initFlags() {
 		usageFilename   = "name of the griddler file to load."
 		defaultUseTrial = false
 		usageUseTrial   = "flag to enable trial&error algorithm"
+		defaultDepth    = 1
+		usageDepth      = "maximum depth of the trial&error search"
+		defaultTimeout  = 0
+		defaultProbing  = false
+		usageProbing    = "flag to try both values of each trial&error candidate"
+		usageTimeout    = "time allowed to the trial&error search or to the repair of an image, e.g. 30s (no limit by default)"
+		defaultVerbose  = true
+		usageVerbose    = "flag to display the solving progress"
+		defaultAlgos    = ""
+		defaultCount    = 0
+		usageCount      = "count the solutions up to the given limit instead of solving"
+		defaultSVGFile  = ""
+		usageSVGFile    = "name of the SVG file where the solved griddler is drawn"
+		defaultPNGFile  = ""
+		usagePNGFile    = "name of the PNG file where the solved griddler is drawn"
+		defaultExport   = ""
+		defaultState    = ""
+		usageState      = "name of a file where the solving state is saved, to be resumed later"
+		usageExport     = "name of a file where the puzzle is converted, in the format of its extension, instead of solving"
+		defaultRepair   = ""
+		defaultGenerate = ""
+		usageGenerate   = "size WxH of a random puzzle generated into the griddler file instead of solving"
+		defaultDensity  = 0.5
+		usageDensity    = "probability of a square to be filled in a generated puzzle"
+		defaultSeed     = 1
+		usageSeed       = "seed of the random puzzle generation, the same seed giving the same puzzle"
+		defaultRule     = "logic"
+		usageRule       = "constraint met by a generated puzzle: logic (solvable by logic alone), unique or none"
+		defaultRate     = false
+		usageRate       = "rate the difficulty of the puzzle from the deductions needed to solve it"
+		defaultHint     = false
+		usageHint       = "show the next deduction to make on the griddler, typically a state file, instead of solving"
+		usageRepair     = "name of a file where the puzzle of the solution image, repaired to be solvable by logic, is written instead of solving, the flipped pixels going to name.diff"
 	)
+	usageAlgos := "comma separated list of algorithms to enable among " + strings.Join(griddler.AlgorithmNames(), ",")
+	var algos string
+
 	flag.StringVar(&fileName, "file", defaultFilename, usageFilename)
 	flag.StringVar(&fileName, "f", defaultFilename, usageFilename)
-	flag.BoolVar(&griddler.UseTrial, "useTrial", defaultUseTrial, usageUseTrial)
+	flag.BoolVar(&opts.UseTrial, "useTrial", defaultUseTrial, usageUseTrial)
+	flag.IntVar(&opts.MaxTrialDepth, "depth", defaultDepth, usageDepth)
+	flag.DurationVar(&opts.TrialTimeout, "timeout", defaultTimeout, usageTimeout)
+	flag.BoolVar(&opts.TwoSidedProbing, "probe", defaultProbing, usageProbing)
+	flag.BoolVar(&verbose, "verbose", defaultVerbose, usageVerbose)
+	flag.StringVar(&algos, "algos", defaultAlgos, usageAlgos)
+	flag.IntVar(&count, "count", defaultCount, usageCount)
+	flag.StringVar(&svgFile, "svg", defaultSVGFile, usageSVGFile)
+	flag.StringVar(&pngFile, "png", defaultPNGFile, usagePNGFile)
+	flag.StringVar(&export, "export", defaultExport, usageExport)
+	flag.StringVar(&state, "state", defaultState, usageState)
+	flag.StringVar(&repair, "repair", defaultRepair, usageRepair)
+	flag.StringVar(&generate, "generate", defaultGenerate, usageGenerate)
+	flag.Float64Var(&density, "density", defaultDensity, usageDensity)
+	flag.Int64Var(&seed, "seed", defaultSeed, usageSeed)
+	flag.StringVar(&rule, "constraint", defaultRule, usageRule)
+	flag.BoolVar(&rate, "rate", defaultRate, usageRate)
+	flag.BoolVar(&hint, "hint", defaultHint, usageHint)
 
 	flag.Parse()
 
@@ -26,23 +98,232 @@ func initFlags() {
 		flag.Usage()
 		os.Exit(1)
 	}
+	if algos != "" {
+		opts.Algorithms = strings.Split(algos, ",")
+	}
+	if verbose {
+		opts.Events = console{}
+	}
 }
 
 func main() {
 	initFlags()
 
-	gBoard := griddler.New()
-	err := gBoard.Load(fileName)
+	if generate != "" {
+		if err := generatePuzzle(); err != nil {
+			fmt.Printf("Error generating puzzle: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	gBoard := griddler.New(opts)
+	err := gBoard.LoadFile(fileName)
+	// report all the problems of the definition at once
+	var perrs griddler.ParseErrors
+	if errors.As(err, &perrs) {
+		err = gBoard.Validate()
+	}
 	if err != nil {
 		fmt.Printf("Error loading file: %v\n", err)
 		return
 	}
 
-	isSolved := gBoard.Solve()
-	if isSolved {
+	if repair != "" {
+		if err := repairPuzzle(gBoard, repair); err != nil {
+			fmt.Printf("Error repairing the puzzle: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if export != "" {
+		if err := gBoard.SaveFile(export); err != nil {
+			fmt.Printf("Error exporting file: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if count > 0 {
+		n, err := gBoard.CountSolutions(count)
+		if errors.Is(err, griddler.ErrSearchTimedOut) {
+			fmt.Printf("Solutions found: %d (limit %d), the search timed out!\n", n, count)
+			return
+		}
+		if err != nil {
+			fmt.Printf("%v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Solutions found: %d (limit %d)\n", n, count)
+		return
+	}
+
+	if hint {
+		h, err := gBoard.Hint()
+		if err != nil {
+			fmt.Printf("%v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(h)
+		return
+	}
+
+	if rate {
+		r, err := gBoard.Rate()
+		if err != nil {
+			fmt.Printf("%v\n", err)
+			os.Exit(1)
+		}
+		printRating(r)
+		return
+	}
+
+	res, err := gBoard.Solve()
+	if err != nil {
+		fmt.Printf("%v\n", err)
+		var serr *griddler.SolveError
+		if errors.As(err, &serr) {
+			fmt.Printf("Please verify your input file...\n")
+		}
+		os.Exit(1)
+	}
+	if res.Solved {
 		fmt.Println("Griddler completed!!!")
+		if goal := gBoard.Goal(); goal != nil && !goal.Equal(gBoard.Solution()) {
+			fmt.Println("The solution found differs from the one of the puzzle definition!")
+		}
+	} else if res.TimedOut {
+		fmt.Println("Griddler uncompleted, the trial&error search timed out!")
 	} else {
 		fmt.Println("Griddler uncompleted, find new search algorithm!")
 	}
 	//gBoard.Show()
+
+	if state != "" {
+		if err := writeState(gBoard, state); err != nil {
+			fmt.Printf("Error writing state file: %v\n", err)
+		}
+	}
+	if svgFile != "" {
+		if err := writeSVG(gBoard, svgFile); err != nil {
+			fmt.Printf("Error writing SVG file: %v\n", err)
+		}
+	}
+	if pngFile != "" {
+		if err := writePNG(gBoard, pngFile); err != nil {
+			fmt.Printf("Error writing PNG file: %v\n", err)
+		}
+	}
+}
+
+func printRating(r *griddler.Rating) {
+	fmt.Printf("Difficulty: %.2f\n", r.Score)
+	for _, name := range r.Algorithms() {
+		fmt.Printf("  %-18s %d squares\n", name, r.Deductions[name])
+	}
+	fmt.Printf("Logic rounds: %d\n", r.Rounds)
+	fmt.Printf("Trial&error needed: %t (%d contradictions)\n", r.Trial, r.Contradictions)
+	if !r.Solved {
+		fmt.Println("Griddler uncompleted, the rating only covers the squares found!")
+	}
+}
+
+func writeSVG(g *griddler.Griddler, filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return g.WriteSVG(f, griddler.SVGOptions{Solved: true})
+}
+
+func writePNG(g *griddler.Griddler, filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return g.WritePNG(f, griddler.ImageOptions{ShowClues: true})
+}
+
+func writeState(g *griddler.Griddler, filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return g.SaveState(f)
+}
+
+var constraints = map[string]griddler.Constraint{
+	"logic":  griddler.SolvableByLogic,
+	"unique": griddler.UniqueSolution,
+	"none":   griddler.AnyPuzzle,
+}
+
+// generatePuzzle writes a random puzzle meeting the constraint in the griddler file
+func generatePuzzle() error {
+	var width, height int
+	if _, err := fmt.Sscanf(generate, "%dx%d", &width, &height); err != nil {
+		return err
+	}
+	c, ok := constraints[rule]
+	if !ok {
+		return errors.New("unknown constraint " + rule)
+	}
+	gen := griddler.Generator{Constraint: c, Solver: opts}
+	g, err := gen.Generate(width, height, density, seed)
+	if err != nil {
+		return err
+	}
+	return g.SaveFile(fileName)
+}
+
+// repairPuzzle writes the puzzle of the solution image made solvable by logic,
+// and the pixels flipped in filename.diff
+func repairPuzzle(g *griddler.Griddler, filename string) error {
+	goal := g.Goal()
+	if goal == nil {
+		return errors.New("the puzzle has no solution image")
+	}
+	ropts := griddler.RepairOptions{Solver: opts, Timeout: opts.TrialTimeout}
+	// the gray levels of an image tell which pixels can be flipped discreetly
+	if f := griddler.FormatByExtension(fileName); f != nil && f.Name == "image" {
+		contrast, err := readContrast(fileName)
+		if err != nil {
+			return err
+		}
+		ropts.Contrast = contrast
+	}
+	r, err := griddler.Repair(goal.Cells(), ropts)
+	if err != nil {
+		return err
+	}
+	if err := r.Griddler.SaveFile(filename); err != nil {
+		return err
+	}
+	f, err := os.Create(filename + ".diff")
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := r.WriteDiff(f); err != nil {
+		return err
+	}
+	fmt.Printf("Pixels flipped: %d\n", len(r.Flips))
+	return f.Close()
+}
+
+func readContrast(filename string) ([][]uint8, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, err
+	}
+	return griddler.Contrast(img, griddler.DefaultThreshold), nil
 }
@@ -3,33 +3,77 @@ package griddler
 // user-defined function to define solving algorithm
 type Algorithm func(g Solver, l *Line)
 
+// namedAlgorithm associates an Algorithm with the name used to select it
+type namedAlgorithm struct {
+	name     string
+	algo     Algorithm
+	colored  bool    // the algorithm supports colored griddlers
+	unknown  bool    // the algorithm supports the lines with unknown clues
+	fallback bool    // the algorithm only runs once the others stall on every line, see solveGeneric
+	weight   float64 // difficulty of its deductions for a human solver, see Rate
+}
+
+// solveAlgorithms is the default solving sequence applied on each line. It ends
+// with solveExact, the complete line solver, so that by default every square
+// forced by a line is found: its cost, O(n·k) time for a line of n squares and
+// k clues, would dominate the solving time of large grids, so it only runs as
+// a fallback, once the heuristics stall on every line, and only on the lines
+// changed since its previous pass. Selecting only the heuristics in
+// SolverOptions.Algorithms avoids it, the puzzles being then less often solved
+// without trial&error.
+var solveAlgorithms = []namedAlgorithm{
+	{"solveFilledRanges", solveFilledRanges, false, false, false, 2},
+	{"solveEmptyRanges", solveEmptyRanges, false, false, false, 2},
+	{"solveAlgo6", solveAlgo6, false, false, false, 3},
+	{"solveAlgo7", solveAlgo7, false, false, false, 3},
+	{"solveAlgo8", solveAlgo8, false, false, false, 3},
+	{"solveExact", solveExact, true, true, true, 4},
+}
+
+// AlgorithmNames returns the names of the line algorithms that can be
+// selected in SolverOptions
+func AlgorithmNames() []string {
+	names := make([]string, len(solveAlgorithms))
+	for i, na := range solveAlgorithms {
+		names[i] = na.name
+	}
+	return names
+}
+
+func findAlgorithm(name string) Algorithm {
+	for _, na := range solveAlgorithms {
+		if na.name == name {
+			return na.algo
+		}
+	}
+	return nil
+}
+
 // algo to be used to solve basic case (empty/full) and initialize clue range
 func solveInitAlgo(g Solver, l *Line) {
 	switch {
+	// the clues of the line are hidden, anything can be found on it
+	case l.isHidden():
 	// no clues are defined for the line, we can blank everything
 	case l.totalClues == 0:
 		for _, s := range l.squares {
 			g.SetValue(s, BLANK)
 		}
-	// the total of the clue is equal to the line length (i.e. one big clue is defined)
-	case l.totalClues == l.length:
-		for _, s := range l.squares {
-			g.SetValue(s, FILLED)
-		}
-	// we initialized all clue ranges and solve the overlap
+	// we initialized all clue ranges and solve the overlap, the whole line being
+	// filled when the clues are as long as the line (the minimal lengths of the
+	// unknown clues giving valid limits and overlaps)
 	default:
+		// the sizes needed before and after the current clue are kept along
+		// the way, lines with many clues would be too slow otherwise
+		sumBegin := 0
+		sumEnd := l.minSize()
 		for i, clue := range l.clues {
-			sumBegin := 0
-			for _, c := range l.clues[0:i] {
-				sumBegin += c.length + 1
-			}
+			sumEnd -= clue.length
 			clue.begin = sumBegin
-			sumEnd := 0
-			for _, c := range l.clues[i+1:] {
-				sumEnd += c.length + 1
-			}
 			clue.end = l.length - 1 - sumEnd
 			clue.solveOverlap()
+			sumEnd -= l.gap(i)
+			sumBegin += clue.length + l.gap(i)
 		}
 	}
 }
@@ -186,6 +230,10 @@ func solveAlgo7(g Solver, l *Line) {
 
 	for _, r := range rsg {
 		cs := l.getPotentialCluesForRange(r)
+		// without any candidate, there is no trail to fill
+		if len(cs) == 0 {
+			continue
+		}
 
 		shortest := l.length
 		isFound, step := l.getStepToNextBlank(r, false)
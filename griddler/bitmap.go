@@ -0,0 +1,125 @@
+package griddler
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	_ "image/gif" // decoders of the images read by ReadBitmap
+	_ "image/png"
+	"io"
+)
+
+// DefaultThreshold is the gray level under which a pixel is considered filled
+// when an image is loaded as a griddler
+const DefaultThreshold = 128
+
+// FromSolution creates a griddler whose clues are derived from its solution,
+// cells[i][j] indicating if the square at the line i and the column j is filled.
+// The solution is kept as the goal of the griddler, see Goal.
+func FromSolution(cells [][]bool) (*Griddler, error) {
+	g := New(SolverOptions{})
+	if err := g.setSolution(cells); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// setSolution initializes the board to the size of the solution and derives
+// the clues of every line and column from it
+func (g *Griddler) setSolution(cells [][]bool) error {
+	if len(cells) == 0 || len(cells[0]) == 0 {
+		return ErrInvalidBitmap
+	}
+	width, height := len(cells[0]), len(cells)
+	for _, row := range cells {
+		if len(row) != width {
+			return ErrInvalidBitmap
+		}
+	}
+
+	g.setSize(width, height)
+	g.goal = newSolution(width, height)
+	for i, row := range cells {
+		g.lines[i].addClues(cluesOf(row))
+		for j, filled := range row {
+			index := backgroundColor
+			if filled {
+				index = defaultColor
+			}
+			g.goal.set(i, j, index)
+		}
+	}
+	column := make([]bool, height)
+	for j := 0; j < width; j++ {
+		for i := range cells {
+			column[i] = cells[i][j]
+		}
+		g.columns[j].addClues(cluesOf(column))
+	}
+	return nil
+}
+
+// cluesOf returns the clues formed by the runs of filled cells
+func cluesOf(cells []bool) [](*Clue) {
+	cs := make([](*Clue), 0)
+	run := 0
+	// the last iteration closes a run reaching the end of the cells
+	for i := 0; i <= len(cells); i++ {
+		if i < len(cells) && cells[i] {
+			run++
+			continue
+		}
+		if run > 0 {
+			cs = append(cs, NewClue(run))
+			run = 0
+		}
+	}
+	return cs
+}
+
+// Bitmap converts an image into a solution matrix, one cell per pixel: the pixels
+// darker than the threshold are filled, the transparent ones being blank
+func Bitmap(img image.Image, threshold uint8) [][]bool {
+	bounds := img.Bounds()
+	cells := make([][]bool, bounds.Dy())
+	for i := range cells {
+		cells[i] = make([]bool, bounds.Dx())
+		for j := range cells[i] {
+			cells[i][j] = grayLevel(img.At(bounds.Min.X+j, bounds.Min.Y+i)) < threshold
+		}
+	}
+	return cells
+}
+
+// grayLevel returns the gray level of the pixel, a transparent one being white
+func grayLevel(px color.Color) uint8 {
+	if _, _, _, a := px.RGBA(); a < 0x8000 {
+		return 0xff
+	}
+	return color.GrayModel.Convert(px).(color.Gray).Y
+}
+
+// ReadBitmap decodes a PNG or GIF image and converts it into a solution matrix,
+// see Bitmap
+func ReadBitmap(r io.Reader, threshold uint8) ([][]bool, error) {
+	img, _, err := image.Decode(r)
+	if err != nil {
+		return nil, err
+	}
+	return Bitmap(img, threshold), nil
+}
+
+// loadImage reads a PNG or GIF image as the solution of the griddler, with the
+// default threshold
+func (g *Griddler) loadImage(r io.Reader) error {
+	cells, err := ReadBitmap(r, DefaultThreshold)
+	if err != nil {
+		return err
+	}
+	return g.setSolution(cells)
+}
+
+// sniffImage detects the signature of a PNG or GIF image
+func sniffImage(data []byte) bool {
+	return bytes.HasPrefix(data, []byte("\x89PNG")) || bytes.HasPrefix(data, []byte("GIF8"))
+}
@@ -0,0 +1,90 @@
+package griddler
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func TestLoadImage(t *testing.T) {
+	// the pixels darker than the default threshold are filled, the transparent
+	// ones being blank whatever their color
+	black, white := color.NRGBA{0, 0, 0, 0xff}, color.NRGBA{0xff, 0xff, 0xff, 0xff}
+	dark, light := color.NRGBA{100, 100, 100, 0xff}, color.NRGBA{200, 200, 200, 0xff}
+	transparent := color.NRGBA{0, 0, 0, 0}
+	pixels := [][]color.NRGBA{
+		{black, dark, black, white},
+		{light, black, transparent, white},
+		{black, black, dark, black},
+	}
+	expected := [][]bool{
+		{true, true, true, false},
+		{false, true, false, false},
+		{true, true, true, true},
+	}
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 3))
+	for i, row := range pixels {
+		for j, px := range row {
+			img.Set(j, i, px)
+		}
+	}
+	var b bytes.Buffer
+	if err := png.Encode(&b, img); err != nil {
+		t.Fatal(err)
+	}
+
+	g := New(SolverOptions{})
+	if err := g.LoadFormat(&b, ""); err != nil {
+		t.Fatal(err)
+	}
+	if g.width != 4 || g.height != 3 {
+		t.Fatalf("got a %dx%d griddler", g.width, g.height)
+	}
+	cells := g.Goal().Cells()
+	for i := range expected {
+		for j := range expected[i] {
+			if cells[i][j] != expected[i][j] {
+				t.Errorf("cell %d,%d is %t", i+1, j+1, cells[i][j])
+			}
+		}
+	}
+
+	// the clues derived from the image lead back to it
+	res, err := g.Solve()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Solved {
+		t.Fatal("not solved")
+	}
+	for i, l := range g.lines {
+		for j, s := range l.squares {
+			if (s.value == FILLED) != expected[i][j] {
+				t.Errorf("square %d,%d is %d", i+1, j+1, s.value)
+			}
+		}
+	}
+}
+
+func TestFromSolution(t *testing.T) {
+	g, err := FromSolution([][]bool{
+		{true, true, false, true},
+		{false, false, false, false},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	if err := g.Save(&b); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "4x2\nH:1;2,1\nH:2;\nV:1;1\nV:2;1\nV:3;\nV:4;1\n"; b.String() != expected {
+		t.Errorf("got\n%s\nexpected\n%s", b.String(), expected)
+	}
+
+	if _, err := FromSolution([][]bool{{true}, {true, false}}); err == nil {
+		t.Error("expected an error for rows of different lengths")
+	}
+}
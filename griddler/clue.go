@@ -2,22 +2,68 @@ package griddler
 
 import (
 	"fmt"
-	"math"
+	"strconv"
+)
+
+// The definition of a griddler can hide the length of a clue with "?", e.g.
+// H:1;2,?,1, or all the clues of a line, their number included, with "*"
+const (
+	unknownClue = "?"
+	hiddenClues = "*"
 )
 
 type Clue struct {
 	l          *Line
 	index      int
-	length     int
+	length     int // length of the clue, the minimal one if it is unknown
+	maxLen     int // maximal length of the clue, equal to length if it is known
+	color      int // palette index of the clue
 	begin, end int
 }
 
 func NewClue(l int) *Clue {
 	return &Clue{
 		length: l,
+		maxLen: l,
+		color:  defaultColor,
+	}
+}
+
+// NewUnknownClue creates a clue whose length is hidden ("?" in the definition),
+// it can be as long as its line
+func NewUnknownClue() *Clue {
+	return &Clue{
+		length: 1,
+		maxLen: -1,
+		color:  defaultColor,
 	}
 }
 
+// isUnknown indicates if the length of the clue is not known exactly
+func (c *Clue) isUnknown() bool {
+	return c.maxLen != c.length
+}
+
+// label returns the clue as written in the definition, its length or "?"
+func (c *Clue) label() string {
+	if c.isUnknown() {
+		return unknownClue
+	}
+	return strconv.Itoa(c.length)
+}
+
+// hasUnknownClues indicates if some clues of the griddler are hidden
+func (g *Griddler) hasUnknownClues() bool {
+	for _, ls := range g.axes {
+		for _, l := range ls {
+			if l.unknown {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func (c *Clue) print(prefix string) {
 	fmt.Printf("%s-->Clue(i:%d,b:%d,e:%d,l:%d)\n", prefix, c.index+1, c.begin+1, c.end+1, c.length)
 }
@@ -26,7 +72,7 @@ func (c *Clue) solveOverlap() {
 	diff := c.begin + c.length - (c.end + 1 - c.length)
 	if diff > 0 {
 		for j := 0; j < diff; j++ {
-			c.l.g.SetValue(c.l.squares[c.end-c.length+1+j], FILLED)
+			c.l.g.SetColor(c.l.squares[c.end-c.length+1+j], c.color)
 		}
 	}
 }
@@ -85,9 +131,11 @@ func maxLength(cs [](*Clue)) int {
 }
 
 func minLength(cs [](*Clue)) int {
-	result := math.MaxUint8 // support for lines of max length 256
-	for _, c := range cs {
-		result = min(result, c.length)
+	result := 0
+	for i, c := range cs {
+		if i == 0 || c.length < result {
+			result = c.length
+		}
 	}
 	return result
 }
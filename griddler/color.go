@@ -0,0 +1,147 @@
+package griddler
+
+import (
+	"encoding/hex"
+	"fmt"
+	"image/color"
+	"math/bits"
+	"strings"
+)
+
+// The squares and clues of a griddler refer to the colors of its palette by
+// index: 0 is the background of the blank squares and 1 the default color of
+// the filled squares, the colored griddlers declaring further colors in the
+// definition with lines of the format C:name;rrggbb (name being a single
+// letter) before the clues using them, e.g. H:1;2r,1,3g
+const (
+	backgroundColor = 0
+	defaultColor    = 1
+	maxColors       = 64 // the colors still possible for a square are kept in a bitmask
+)
+
+// Color is an entry of the palette of a griddler
+type Color struct {
+	Name string     // letter used in the definition, empty for the background and the default color
+	RGB  color.RGBA // color used to render the squares and the clues
+}
+
+func defaultPalette() []Color {
+	return []Color{
+		{RGB: color.RGBA{0xff, 0xff, 0xff, 0xff}},
+		{RGB: color.RGBA{0x00, 0x00, 0x00, 0xff}},
+	}
+}
+
+// Colors returns the palette of the griddler, see AddColor
+func (g *Griddler) Colors() []Color {
+	return g.palette
+}
+
+// AddColor declares a new color for the clues and returns its palette index
+func (g *Griddler) AddColor(name string, rgb color.RGBA) (int, error) {
+	if !isColorName(name) {
+		return 0, ErrInvalidColorName
+	}
+	if g.colorIndex(name) >= 0 {
+		return 0, ErrDuplicateColor
+	}
+	if len(g.palette) >= maxColors {
+		return 0, ErrTooManyColors
+	}
+	g.palette = append(g.palette, Color{Name: name, RGB: rgb})
+	return len(g.palette) - 1, nil
+}
+
+// colorIndex returns the palette index of the named color, the empty name being
+// the default color, or -1 if the color is unknown
+func (g *Griddler) colorIndex(name string) int {
+	if name == "" {
+		return defaultColor
+	}
+	for i, c := range g.palette {
+		if i > defaultColor && c.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// isColored indicates if the griddler uses other colors than the default one
+func (g *Griddler) isColored() bool {
+	return len(g.palette) > defaultColor+1
+}
+
+// allColors returns the mask of every palette index, the background included
+func (g *Griddler) allColors() uint64 {
+	return 1<<uint(len(g.palette)) - 1
+}
+
+// possible returns the mask of the palette indexes the square can still take
+func (g *Griddler) possible(s *Square) uint64 {
+	switch {
+	case s.value != EMPTY:
+		return 1 << uint(s.color)
+	case s.colors == 0:
+		return g.allColors()
+	}
+	return s.colors
+}
+
+// states returns the palette indexes the square can still take, starting with
+// the preferred one
+func (g *Griddler) states(s *Square, preferred int) []int {
+	result := []int{preferred}
+	for mask := g.possible(s) &^ (1 << uint(preferred)); mask != 0; mask &= mask - 1 {
+		result = append(result, bits.TrailingZeros64(mask))
+	}
+	return result
+}
+
+// firstColor returns the lowest color, other than the background, still possible
+// for an empty square
+func (g *Griddler) firstColor(s *Square) int {
+	mask := g.possible(s) &^ (1 << backgroundColor)
+	if mask == 0 {
+		return backgroundColor
+	}
+	return bits.TrailingZeros64(mask)
+}
+
+// parseColorLine reads the definition of a color, name;rrggbb
+func (g *Griddler) parseColorLine(name, rgb string) error {
+	c, err := parseRGB(rgb)
+	if err != nil {
+		return err
+	}
+	_, err = g.AddColor(strings.TrimSpace(name), c)
+	return err
+}
+
+// parseRGB reads an hexadecimal color of the format rrggbb or rgb, with an
+// optional leading #
+func parseRGB(s string) (color.RGBA, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "#")
+	if len(s) == 3 {
+		s = string([]byte{s[0], s[0], s[1], s[1], s[2], s[2]})
+	}
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != 3 {
+		return color.RGBA{}, ErrInvalidColorValue
+	}
+	return color.RGBA{b[0], b[1], b[2], 0xff}, nil
+}
+
+// hexRGB formats the color as rrggbb
+func hexRGB(c color.RGBA) string {
+	return fmt.Sprintf("%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// isColorName indicates if the name is a single letter other than the chars of
+// the squares in a state file, which could not tell the color apart
+func isColorName(name string) bool {
+	return len(name) == 1 && isLetter(name[0]) && name[0] != squareChars[FILLED]
+}
+
+func isLetter(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
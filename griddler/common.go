@@ -7,8 +7,9 @@ type Tile struct {
 }
 
 type Solver interface {
-	Solve() bool
+	Solve() (Result, error)
 	SetValue(square *Square, value int)
+	SetColor(square *Square, color int)
 }
 
 // utility struc Range
@@ -3,6 +3,7 @@ package griddler
 import (
 	"errors"
 	"fmt"
+	"strings"
 )
 
 type ParseError struct {
@@ -11,9 +12,36 @@ type ParseError struct {
 }
 
 func (e *ParseError) Error() string {
+	if e.line == 0 {
+		return fmt.Sprintf("Error: %s", e.err)
+	}
 	return fmt.Sprintf("Error line %d: %s", e.line, e.err)
 }
 
+func (e *ParseError) Unwrap() error {
+	return e.err
+}
+
+// ParseErrors gathers all the problems found in a griddler definition
+type ParseErrors [](*ParseError)
+
+func (es ParseErrors) Error() string {
+	msgs := make([]string, len(es))
+	for i, e := range es {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap gives access to every problem, e.g. for errors.Is
+func (es ParseErrors) Unwrap() []error {
+	errs := make([]error, len(es))
+	for i, e := range es {
+		errs[i] = e
+	}
+	return errs
+}
+
 var (
 	ErrInvalidGridSizeFormat = errors.New("invalid format for first line")
 	ErrInvalidGridSizeValue  = errors.New("invalid value for griddler size")
@@ -22,10 +50,46 @@ var (
 	ErrInvalidIntLine        = errors.New("invalid integer for line info")
 	ErrInvalidTokenLine      = errors.New("invalid starting token for line info")
 	ErrTooManyLine           = errors.New("too many line compared to the size specified")
+	ErrUnsupportedPuzzle     = errors.New("unsupported type of puzzle")
+	ErrMissingClues          = errors.New("missing row or column clues")
+	ErrInvalidSolutionImage  = errors.New("the solution image does not match the griddler size")
+	ErrInvalidBitmap         = errors.New("the solution must be a non-empty rectangular matrix")
+	ErrRepairFailed          = errors.New("no pixel flip makes the puzzle solvable by logic")
+	ErrRepairTimedOut        = errors.New("the repair timed out before the puzzle became solvable by logic")
+	ErrInvalidDensity        = errors.New("the density must be between 0 and 1")
+	ErrGenerateFailed        = errors.New("no random grid met the constraint within the allowed attempts")
+	ErrNoHint                = errors.New("no square can be deduced from a single line")
+	ErrUnknownFormat         = errors.New("unknown puzzle format")
+	ErrUnsupportedWrite      = errors.New("the puzzle format can not be written")
+	ErrInvalidStateFormat    = errors.New("invalid content for a state file")
+	ErrDuplicateLine         = errors.New("line info already defined")
+	ErrInvalidClueValue      = errors.New("clue values must be positive, 0 being only allowed alone for an empty line")
+	ErrCluesTooLong          = errors.New("the clues and their gaps exceed the line length")
+	ErrTotalsMismatch        = errors.New("the sum of the line clues differs from the sum of the column clues")
+	ErrInvalidColorName      = errors.New("a color name must be a single letter other than X")
+	ErrInvalidColorValue     = errors.New("invalid hexadecimal value for color")
+	ErrDuplicateColor        = errors.New("color already defined")
+	ErrTooManyColors         = errors.New("too many colors defined")
+	ErrUnknownColor          = errors.New("unknown color for clue")
+	ErrUnsupportedColors     = errors.New("the puzzle format does not support colors")
+	ErrMisplacedHiddenClues  = errors.New("hidden clues (*) must be alone on their line")
+	ErrUnsupportedUnknown    = errors.New("the puzzle format does not support unknown clues")
+	ErrInvalidMultiPart      = errors.New("the griddler is already being solved or part of a multi-griddler")
+	ErrPaletteMismatch       = errors.New("the griddlers of a multi-griddler must have the same colors")
+)
+
+var (
+	ErrInvalidTrialDepth = errors.New("the maximum trial depth can not be negative")
+	ErrUnknownAlgorithm  = errors.New("unknown algorithm name in the solver options")
+	ErrSearchTimedOut    = errors.New("the search of the solutions timed out before its end")
 )
 
+// SolveError describes a contradiction found while solving, it references the
+// offending square or the line (and clue) on which the contradiction appeared
 type SolveError struct {
 	s   *Square
+	l   *Line
+	c   *Clue
 	err error
 }
 
@@ -33,8 +97,58 @@ var (
 	ErrOverridingValue  = errors.New("attempt to override an existing different value")
 	ErrInvalidClueRange = errors.New("too many clues are present on the line/column")
 	ErrInvalidClueSize  = errors.New("the limits of the clue has been reduced to a size less than its length")
+	ErrNoValidPlacement = errors.New("no placement of the clues is compatible with the line")
+	ErrInvalidSolution  = errors.New("the completed line does not match its clues")
 )
 
 func (e *SolveError) Error() string {
-	return fmt.Sprintf("Error on line %d, or column %d: %s", e.s.x+1, e.s.y+1, e.err)
+	switch {
+	case e.s != nil:
+		return fmt.Sprintf("Error on line %d, column %d: %s", e.s.x+1, e.s.y+1, e.err)
+	case e.c != nil:
+		return fmt.Sprintf("Error on %s %d, clue %d of length %d: %s", e.l.kind(), e.l.index+1, e.c.index+1, e.c.length, e.err)
+	case e.l != nil:
+		return fmt.Sprintf("Error on %s %d: %s", e.l.kind(), e.l.index+1, e.err)
+	}
+	return fmt.Sprintf("Error: %s", e.err)
+}
+
+// Unwrap gives access to the underlying error, e.g. ErrOverridingValue
+func (e *SolveError) Unwrap() error {
+	return e.err
+}
+
+// Position returns the 1-based line and column where the contradiction was found,
+// 0 being returned when the information is not relevant (e.g. column of a line
+// error, or both for a diagonal of a triddler)
+func (e *SolveError) Position() (line, column int) {
+	switch {
+	case e.s != nil:
+		return e.s.x + 1, e.s.y + 1
+	case e.l != nil && e.l.isColumn():
+		return 0, e.l.index + 1
+	case e.l != nil && e.l.axis == 0:
+		return e.l.index + 1, 0
+	}
+	return 0, 0
+}
+
+// Clue returns the 1-based index and the length of the offending clue, if any
+func (e *SolveError) Clue() (index, length int) {
+	if e.c == nil {
+		return 0, 0
+	}
+	return e.c.index + 1, e.c.length
+}
+
+// recoverSolveError is meant to be deferred, it converts a SolveError raised
+// by the solving algorithms into a returned error
+func recoverSolveError(err *error) {
+	if r := recover(); r != nil {
+		if serr, ok := r.(*SolveError); ok {
+			*err = serr
+		} else {
+			panic(r)
+		}
+	}
 }
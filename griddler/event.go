@@ -0,0 +1,53 @@
+package griddler
+
+// EventKind identifies the step of the solving process reported by an Event
+type EventKind int
+
+const (
+	PhaseStart    EventKind = iota // a solving phase begins, see Event.Phase
+	SquareDeduced                  // a square value has been found
+	TrialStart                     // a trial&error attempt begins on a square
+	TrialResult                    // a trial&error attempt ends, Event.Err is set on contradiction
+	Contradiction                  // the current hypothesis (or the puzzle) is contradictory
+	SolveEnd                       // the solving process is over, see Event.Result
+)
+
+// Phases of the solving process
+const (
+	PhaseLogic  = "logic"
+	PhaseTrial  = "trial"
+	PhaseSearch = "search"
+)
+
+// Event describes a step of the solving process
+type Event struct {
+	Kind   EventKind
+	Phase  string // current phase of the solving process
+	Line   int    // 1-based line of the square concerned, if any
+	Column int    // 1-based column of the square concerned, if any
+	Value  int    // value deduced or tried for the square
+	Color  int    // palette index deduced or tried for the square, see Griddler.Colors
+	// trial&error phase counters
+	Selected  int // number of squares selected as trial candidates
+	Potential int // number of squares still empty
+	Total     int // number of squares of the board
+	Attempt   int // 1-based index of the current attempt
+	Result    *Result
+	Err       error
+}
+
+// EventSink receives the events emitted while solving a griddler, it allows to
+// follow the progress without the library writing anything by itself
+type EventSink interface {
+	Event(g *Griddler, e Event)
+}
+
+// emit forwards the event to the sink of the options, if any
+func (g *Griddler) emit(e Event) {
+	if g.opts.Events != nil {
+		if e.Phase == "" {
+			e.Phase = g.phase
+		}
+		g.opts.Events.Event(g, e)
+	}
+}
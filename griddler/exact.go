@@ -0,0 +1,209 @@
+package griddler
+
+// solveExact is a complete line solver based on dynamic programming: for each
+// square, it determines the colors (the background included) it takes in at
+// least one placement of the clues compatible with the current state of the
+// line. The squares having a single possibility are set, the others keep the
+// remaining possibilities, and the clue limits are reduced to the extreme
+// valid placements. The clues of unknown length are tried with every length of
+// their range, and a line whose clues are hidden gives no information.
+func solveExact(g Solver, l *Line) {
+	if l.isHidden() {
+		return
+	}
+	n := l.length
+	// the clue of length 0 is only the notation of an empty line
+	cs := make([](*Clue), 0, len(l.clues))
+	for _, c := range l.clues {
+		if c.length > 0 {
+			cs = append(cs, c)
+		}
+	}
+	k := len(cs)
+
+	// forbidden[color][i] is the number of squares in [0,i) which can not take
+	// the color, to check quickly that a clue can be placed on a segment, the
+	// slices being indexed by palette index and only set for the colors in use
+	colors := len(l.g.palette)
+	t := &l.g.exact
+	t.reset(n, k, colors)
+	forbidden := make([][]int, colors)
+	for _, color := range append(clueColors(cs), backgroundColor) {
+		if forbidden[color] != nil {
+			continue
+		}
+		counts := t.ints(n + 1)
+		for i, s := range l.squares {
+			counts[i+1] = counts[i]
+			if !s.canBe(color) {
+				counts[i+1]++
+			}
+		}
+		forbidden[color] = counts
+	}
+	fits := func(begin, end, color int) bool {
+		counts := forbidden[color]
+		return begin >= 0 && end <= n && counts[end] == counts[begin]
+	}
+	canBlank := func(i int) bool {
+		return l.squares[i].canBe(backgroundColor)
+	}
+	// touching indicates if the clues j-1 and j can be placed without gap
+	touching := func(j int) bool {
+		return j > 0 && j < k && cs[j-1].color != cs[j].color
+	}
+
+	// free[i][j] is true if the first j clues can be placed in the squares [0,i)
+	// with the square i-1 blank (or i = 0), last[i][j] if they can be placed with
+	// the clue j-1 ending on the square i-1
+	free := t.boolMatrix(n+1, k+1)
+	last := t.boolMatrix(n+1, k+1)
+	free[0][0] = true
+	for i := 1; i <= n; i++ {
+		for j := 0; j <= k; j++ {
+			free[i][j] = canBlank(i-1) && (free[i-1][j] || last[i-1][j])
+			if j > 0 {
+				// the longer lengths do not fit either once a length does not
+				c := cs[j-1]
+				for b := i - c.length; i-b <= c.maxLen && fits(b, i, c.color); b-- {
+					if free[b][j-1] || last[b][j-1] && touching(j-1) {
+						last[i][j] = true
+						break
+					}
+				}
+			}
+		}
+	}
+
+	// bfree[i][j] is true if the clues [j,k) can be placed in the squares [i,n)
+	// with the square i blank (or i = n), first[i][j] if they can be placed with
+	// the clue j starting on the square i
+	bfree := t.boolMatrix(n+1, k+1)
+	first := t.boolMatrix(n+1, k+1)
+	bfree[n][k] = true
+	for i := n - 1; i >= 0; i-- {
+		for j := k; j >= 0; j-- {
+			bfree[i][j] = canBlank(i) && (bfree[i+1][j] || first[i+1][j])
+			if j < k {
+				c := cs[j]
+				for e := i + c.length; e-i <= c.maxLen && fits(i, e, c.color); e++ {
+					if bfree[e][j+1] || first[e][j+1] && touching(j+1) {
+						first[i][j] = true
+						break
+					}
+				}
+			}
+		}
+	}
+
+	if !free[n][k] && !last[n][k] {
+		panic(&SolveError{l: l, err: ErrNoValidPlacement})
+	}
+
+	// squares covered by at least one valid placement of a clue, by color, as
+	// the differences of the number of placements from a square to the next one
+	cover := make([][]int, colors)
+	for j, c := range cs {
+		if cover[c.color] == nil {
+			cover[c.color] = t.ints(n + 1)
+		}
+		firstBegin, lastEnd := -1, -1
+		for b := 0; b+c.length <= n; b++ {
+			if !(free[b][j] || last[b][j] && touching(j)) {
+				continue
+			}
+			for e := b + c.length; e-b <= c.maxLen && fits(b, e, c.color); e++ {
+				if bfree[e][j+1] || first[e][j+1] && touching(j+1) {
+					cover[c.color][b]++
+					cover[c.color][e]--
+					if firstBegin < 0 {
+						firstBegin = b
+					}
+					lastEnd = max(lastEnd, e-1)
+				}
+			}
+		}
+		c.begin = max(c.begin, firstBegin)
+		c.end = min(c.end, lastEnd)
+	}
+
+	masks := make([]uint64, n)
+	covered := make([]int, colors)
+	for i, s := range l.squares {
+		for color, diff := range cover {
+			if diff != nil {
+				covered[color] += diff[i]
+			}
+		}
+		if s.value != EMPTY {
+			continue
+		}
+		for j := 0; j <= k; j++ {
+			if free[i+1][j] && (bfree[i+1][j] || first[i+1][j]) {
+				masks[i] |= 1 << backgroundColor
+				break
+			}
+		}
+		for color, count := range covered {
+			if count > 0 {
+				masks[i] |= 1 << uint(color)
+			}
+		}
+	}
+	for i, mask := range masks {
+		if l.squares[i].value == EMPTY {
+			l.g.restrict(l.squares[i], mask)
+		}
+	}
+}
+
+// clueColors returns the palette index of each clue
+func clueColors(cs [](*Clue)) []int {
+	colors := make([]int, len(cs))
+	for i, c := range cs {
+		colors[i] = c.color
+	}
+	return colors
+}
+
+// exactTables keeps the memory of the tables of solveExact, the lines of large
+// grids needing big tables: it is only allocated again for a longer line, more
+// clues or more colors
+type exactTables struct {
+	cells  []bool // backing array of the four boolean tables
+	counts []int  // backing array of the tables of each color
+	nCells int    // cells already given to the line being solved
+	nCount int    // counts already given to the line being solved
+}
+
+// reset prepares the tables for a line of n squares, k clues and the given
+// number of colors
+func (t *exactTables) reset(n, k, colors int) {
+	if cells := 4 * (n + 1) * (k + 1); cap(t.cells) < cells {
+		t.cells = make([]bool, cells)
+	}
+	if counts := 2 * colors * (n + 1); cap(t.counts) < counts {
+		t.counts = make([]int, counts)
+	}
+	t.nCells, t.nCount = 0, 0
+}
+
+// boolMatrix returns a rows x cols matrix set to false, taken from the cells
+func (t *exactTables) boolMatrix(rows, cols int) [][]bool {
+	cells := t.cells[t.nCells : t.nCells+rows*cols]
+	t.nCells += rows * cols
+	clear(cells)
+	m := make([][]bool, rows)
+	for i := range m {
+		m[i] = cells[i*cols : (i+1)*cols]
+	}
+	return m
+}
+
+// ints returns a slice of size zeros, taken from the counts
+func (t *exactTables) ints(size int) []int {
+	counts := t.counts[t.nCount : t.nCount+size]
+	t.nCount += size
+	clear(counts)
+	return counts
+}
@@ -0,0 +1,197 @@
+package griddler
+
+import (
+	"errors"
+	"image/color"
+	"math/rand"
+	"testing"
+)
+
+// testClue is a clue of a test line, its length ranging from min to max
+type testClue struct {
+	min, max, color int
+}
+
+// newTestLine creates a single line griddler with the given clues and squares,
+// the squares being palette indexes or -1 for an empty one
+func newTestLine(t *testing.T, colors int, clues []testClue, squares []int) (*Griddler, *Line) {
+	t.Helper()
+	g := New(SolverOptions{})
+	g.setSize(len(squares), 1)
+	for i := 2; i <= colors; i++ {
+		if _, err := g.AddColor(string(rune('a'+i-2)), color.RGBA{A: 0xff}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	l := g.lines[0]
+	cs := make([](*Clue), len(clues))
+	for i, tc := range clues {
+		cs[i] = &Clue{length: tc.min, maxLen: tc.max, color: tc.color}
+	}
+	l.addClues(cs)
+	for _, c := range cs {
+		c.begin, c.end = 0, l.length-1
+	}
+	for i, state := range squares {
+		if state >= 0 {
+			l.squares[i].value = valueOf(state)
+			l.squares[i].color = state
+		}
+	}
+	g.recount()
+	return g, l
+}
+
+// matchesTestClues indicates if the squares form exactly the runs of the clues,
+// a run gathering the consecutive squares of a color so that two clues of the
+// same color are always separated
+func matchesTestClues(squares []int, clues []testClue) bool {
+	k := 0
+	for i := 0; i < len(squares); {
+		if squares[i] == backgroundColor {
+			i++
+			continue
+		}
+		j := i
+		for j < len(squares) && squares[j] == squares[i] {
+			j++
+		}
+		if k >= len(clues) || clues[k].color != squares[i] || j-i < clues[k].min || j-i > clues[k].max {
+			return false
+		}
+		k++
+		i = j
+	}
+	return k == len(clues)
+}
+
+// bruteForceLine returns for each square the mask of the palette indexes it
+// takes in at least one valid placement, nil if there is none
+func bruteForceLine(colors int, clues []testClue, squares []int) []uint64 {
+	n := len(squares)
+	masks := make([]uint64, n)
+	candidate := make([]int, n)
+	valid := false
+	var enumerate func(i int)
+	enumerate = func(i int) {
+		if i == n {
+			if matchesTestClues(candidate, clues) {
+				valid = true
+				for j, c := range candidate {
+					masks[j] |= 1 << uint(c)
+				}
+			}
+			return
+		}
+		for c := 0; c <= colors; c++ {
+			if squares[i] < 0 || squares[i] == c {
+				candidate[i] = c
+				enumerate(i + 1)
+			}
+		}
+	}
+	enumerate(0)
+	if !valid {
+		return nil
+	}
+	return masks
+}
+
+// checkSolveExact compares solveExact to the brute force enumeration
+func checkSolveExact(t *testing.T, colors int, clues []testClue, squares []int) {
+	t.Helper()
+	expected := bruteForceLine(colors, clues, squares)
+	g, l := newTestLine(t, colors, clues, squares)
+	err := func() (err error) {
+		defer recoverSolveError(&err)
+		solveExact(g, l)
+		return
+	}()
+	if expected == nil {
+		if !errors.Is(err, ErrNoValidPlacement) && !errors.Is(err, ErrOverridingValue) {
+			t.Errorf("clues %v, squares %v: no contradiction found, got %v", clues, squares, err)
+		}
+		return
+	}
+	if err != nil {
+		t.Errorf("clues %v, squares %v: unexpected %v", clues, squares, err)
+		return
+	}
+	for i, s := range l.squares {
+		for c := 0; c <= colors; c++ {
+			if possible := expected[i]&(1<<uint(c)) != 0; s.canBe(c) != possible {
+				t.Errorf("clues %v, squares %v: square %d can be %d is %t, expected %t", clues, squares, i, c, s.canBe(c), possible)
+			}
+		}
+	}
+}
+
+func TestSolveExact(t *testing.T) {
+	const e = -1
+	tests := []struct {
+		name    string
+		colors  int
+		clues   []testClue
+		squares []int
+	}{
+		{"overlap", 1, []testClue{{3, 3, 1}}, []int{e, e, e, e}},
+		{"full line", 1, []testClue{{2, 2, 1}, {2, 2, 1}}, []int{e, e, e, e, e}},
+		{"empty line", 1, nil, []int{e, e, e}},
+		{"anchored", 1, []testClue{{1, 1, 1}, {2, 2, 1}}, []int{e, e, 1, e, e, e}},
+		{"blank split", 1, []testClue{{3, 3, 1}}, []int{e, e, 0, e, e, e}},
+		{"contradiction", 1, []testClue{{2, 2, 1}}, []int{1, 0, 1, e}},
+		{"too long", 1, []testClue{{4, 4, 1}}, []int{e, e, e}},
+		{"touching colors", 2, []testClue{{2, 2, 1}, {2, 2, 2}}, []int{e, e, e, e}},
+		{"colors with gap", 2, []testClue{{1, 1, 2}, {1, 1, 1}, {1, 1, 1}}, []int{e, e, e, e}},
+		{"unknown length", 1, []testClue{{1, 5, 1}, {2, 2, 1}}, []int{e, e, 1, e, e, e}},
+		{"unknown colored", 2, []testClue{{1, 3, 2}, {1, 1, 1}}, []int{e, e, e, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkSolveExact(t, tt.colors, tt.clues, tt.squares)
+		})
+	}
+}
+
+func TestSolveExactRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for n := 0; n < 2000; n++ {
+		colors := 1 + r.Intn(3)
+		length := 1 + r.Intn(8)
+
+		// the clues are derived from a random line, so that most are valid
+		line := make([]int, length)
+		for i := range line {
+			if r.Intn(2) == 0 {
+				line[i] = 1 + r.Intn(colors)
+			}
+		}
+		clues := make([]testClue, 0)
+		for i := 0; i < length; {
+			j := i
+			for j < length && line[j] == line[i] {
+				j++
+			}
+			if line[i] != backgroundColor {
+				tc := testClue{j - i, j - i, line[i]}
+				if r.Intn(5) == 0 {
+					tc.min, tc.max = 1, length
+				}
+				clues = append(clues, tc)
+			}
+			i = j
+		}
+
+		squares := make([]int, length)
+		for i := range squares {
+			squares[i] = -1
+			switch r.Intn(6) {
+			case 0:
+				squares[i] = line[i]
+			case 1:
+				squares[i] = r.Intn(colors + 1)
+			}
+		}
+		checkSolveExact(t, colors, clues, squares)
+	}
+}
@@ -0,0 +1,244 @@
+package griddler
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Format describes a puzzle file format which can be read and possibly written
+type Format struct {
+	Name       string
+	Extensions []string                             // file extensions, e.g. ".grid"
+	Sniff      func(data []byte) bool               // detects the format from the beginning of the content
+	Read       func(g *Griddler, r io.Reader) error // loads the puzzle into an empty griddler
+	Write      func(g *Griddler, w io.Writer) error // writes the clues of the puzzle, nil if not supported
+}
+
+var formats [](*Format)
+
+// RegisterFormat makes a format available to the detection and to LoadFile/SaveFile
+func RegisterFormat(f *Format) {
+	formats = append(formats, f)
+}
+
+// Formats returns the registered formats
+func Formats() [](*Format) {
+	return formats
+}
+
+// FormatByName returns the registered format of the given name, nil if unknown
+func FormatByName(name string) *Format {
+	for _, f := range formats {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// FormatByExtension returns the registered format associated with the extension
+// of the file name, nil if unknown
+func FormatByExtension(filename string) *Format {
+	ext := strings.ToLower(filepath.Ext(filename))
+	for _, f := range formats {
+		for _, e := range f.Extensions {
+			if e == ext {
+				return f
+			}
+		}
+	}
+	return nil
+}
+
+// SniffFormat returns the first registered format recognizing the content, nil if none
+func SniffFormat(data []byte) *Format {
+	for _, f := range formats {
+		if f.Sniff != nil && f.Sniff(data) {
+			return f
+		}
+	}
+	return nil
+}
+
+// LoadFile reads a puzzle file whose format is detected by its extension or,
+// failing that, by its content
+func (g *Griddler) LoadFile(filename string) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	f := FormatByExtension(filename)
+	if f == nil {
+		f = SniffFormat(data)
+	}
+	if f == nil {
+		return ErrUnknownFormat
+	}
+	return f.Read(g, bytes.NewReader(data))
+}
+
+// LoadFormat reads a puzzle in the named format, the format being detected from
+// the content if name is empty
+func (g *Griddler) LoadFormat(r io.Reader, name string) error {
+	if name != "" {
+		f := FormatByName(name)
+		if f == nil {
+			return ErrUnknownFormat
+		}
+		return f.Read(g, r)
+	}
+	br := bufio.NewReader(r)
+	data, _ := br.Peek(sniffLength)
+	f := SniffFormat(data)
+	if f == nil {
+		return ErrUnknownFormat
+	}
+	return f.Read(g, br)
+}
+
+// WriteFormat writes the puzzle in the named format
+func (g *Griddler) WriteFormat(w io.Writer, name string) error {
+	f := FormatByName(name)
+	if f == nil {
+		return ErrUnknownFormat
+	}
+	if f.Write == nil {
+		return ErrUnsupportedWrite
+	}
+	return f.Write(g, w)
+}
+
+// SaveFile writes the puzzle in the format associated with the file extension
+func (g *Griddler) SaveFile(filename string) error {
+	f := FormatByExtension(filename)
+	if f == nil {
+		return ErrUnknownFormat
+	}
+	if f.Write == nil {
+		return ErrUnsupportedWrite
+	}
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if err := f.Write(g, file); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// number of bytes looked at to detect the format of a content
+const sniffLength = 512
+
+var (
+	gridHeader         = regexp.MustCompile(`^\s*\d+x\d+\s*(\n|$)`)
+	triddlerSizeHeader = regexp.MustCompile(`^\s*` + triddlerHeader + `\d+\s*(\n|$)`)
+)
+
+func init() {
+	RegisterFormat(&Format{
+		Name:       "grid",
+		Extensions: []string{".grid"},
+		Sniff: func(data []byte) bool {
+			return gridHeader.Match(data)
+		},
+		Read:  (*Griddler).LoadFrom,
+		Write: (*Griddler).Save,
+	})
+	RegisterFormat(&Format{
+		Name:       "triddler",
+		Extensions: []string{".tri"},
+		Sniff: func(data []byte) bool {
+			return triddlerSizeHeader.Match(data)
+		},
+		Read:  (*Griddler).LoadFrom,
+		Write: (*Griddler).Save,
+	})
+	RegisterFormat(&Format{
+		Name:       "webpbn",
+		Extensions: []string{".xml", ".pbn"},
+		Sniff: func(data []byte) bool {
+			return bytes.Contains(data, []byte("<puzzle"))
+		},
+		Read: (*Griddler).LoadWebpbn,
+	})
+	RegisterFormat(&Format{
+		Name:       "state",
+		Extensions: []string{".state"},
+		Sniff: func(data []byte) bool {
+			return bytes.HasPrefix(data, []byte(stateHeader))
+		},
+		Read:  (*Griddler).LoadState,
+		Write: (*Griddler).SaveState,
+	})
+	RegisterFormat(&Format{
+		Name:       "non",
+		Extensions: []string{".non"},
+		Sniff:      sniffNon,
+		Read:       (*Griddler).loadNon,
+		Write:      (*Griddler).writeNon,
+	})
+	RegisterFormat(&Format{
+		Name:       "olsak",
+		Extensions: []string{".g"},
+		Sniff:      sniffOlsak,
+		Read:       (*Griddler).loadOlsak,
+		Write:      (*Griddler).writeOlsak,
+	})
+	RegisterFormat(&Format{
+		Name:       "image",
+		Extensions: []string{".png", ".gif"},
+		Sniff:      sniffImage,
+		Read:       (*Griddler).loadImage,
+	})
+}
+
+// clueLengths returns the lengths of the clues of the line, without the
+// notation of an empty line
+func clueLengths(l *Line) []int {
+	lengths := make([]int, 0, len(l.clues))
+	for _, c := range l.clues {
+		if c.length > 0 {
+			lengths = append(lengths, c.length)
+		}
+	}
+	return lengths
+}
+
+// parseClues reads a list of clue lengths separated by commas or spaces, an
+// empty list or a single 0 meaning an empty line
+func parseClues(s string) ([](*Clue), error) {
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+	cs := make([](*Clue), 0, len(fields))
+	for _, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil {
+			return nil, ErrInvalidIntValue
+		}
+		if n > 0 {
+			cs = append(cs, NewClue(n))
+		}
+	}
+	return cs, nil
+}
+
+// joinClues formats the clue lengths with the given separator, "0" for an empty line
+func joinClues(lengths []int, sep string) string {
+	if len(lengths) == 0 {
+		return "0"
+	}
+	s := make([]string, len(lengths))
+	for i, n := range lengths {
+		s[i] = strconv.Itoa(n)
+	}
+	return strings.Join(s, sep)
+}
@@ -0,0 +1,98 @@
+package griddler
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// checkFormat loads the content, whose format must be detected, and compares
+// the griddler read to its definition in the native format
+func checkFormat(t *testing.T, content, format, expected string) *Griddler {
+	t.Helper()
+	if f := SniffFormat([]byte(content)); f == nil || f.Name != format {
+		t.Fatalf("format %s not detected", format)
+	}
+	g := New(SolverOptions{})
+	if err := g.LoadFormat(strings.NewReader(content), ""); err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	if err := g.Save(&b); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != expected {
+		t.Errorf("read as:\n%s\nexpected:\n%s", b.String(), expected)
+	}
+	return g
+}
+
+// checkWriteFormat writes the griddler in the format and reads it back
+func checkWriteFormat(t *testing.T, g *Griddler, format string) {
+	t.Helper()
+	var written, first, second bytes.Buffer
+	if err := g.WriteFormat(&written, format); err != nil {
+		t.Fatal(err)
+	}
+	read := New(SolverOptions{})
+	if err := read.LoadFormat(bytes.NewReader(written.Bytes()), ""); err != nil {
+		t.Fatalf("%v\n%s", err, written.String())
+	}
+	if err := g.Save(&first); err != nil {
+		t.Fatal(err)
+	}
+	if err := read.Save(&second); err != nil {
+		t.Fatal(err)
+	}
+	if first.String() != second.String() {
+		t.Errorf("%s round trip differs:\n%s\n%s", format, first.String(), second.String())
+	}
+}
+
+func TestNonFormat(t *testing.T) {
+	content := "width 3\nheight 2\n\nrows\n1,1\n3\n\ncolumns\n2\n1\n2\ngoal \"101111\"\n"
+	g := checkFormat(t, content, "non", "3x2\nH:1;1,1\nH:2;3\nV:1;2\nV:2;1\nV:3;2\n")
+	goal := g.Goal()
+	if goal == nil || goal.Filled(0, 1) || !goal.Filled(1, 1) {
+		t.Errorf("goal not read: %v", goal)
+	}
+	checkWriteFormat(t, g, "non")
+
+	g = New(SolverOptions{})
+	if err := g.LoadFormat(strings.NewReader("width 3\nheight 2\nrows\n1\n"), "non"); !errors.Is(err, ErrMissingClues) {
+		t.Errorf("expected %v, got %v", ErrMissingClues, err)
+	}
+}
+
+func TestOlsakFormat(t *testing.T) {
+	content := "# 3x2\n: rows\n1 1\n3\n: columns\n2\n1\n2\n"
+	g := checkFormat(t, content, "olsak", "3x2\nH:1;1,1\nH:2;3\nV:1;2\nV:2;1\nV:3;2\n")
+	checkWriteFormat(t, g, "olsak")
+
+	g = New(SolverOptions{})
+	if err := g.LoadFormat(strings.NewReader("# 3x2\n: rows\n1 1\n3\n"), "olsak"); !errors.Is(err, ErrMissingClues) {
+		t.Errorf("expected %v, got %v", ErrMissingClues, err)
+	}
+}
+
+func TestOlsakColors(t *testing.T) {
+	content := "#d\n" +
+		"   0:   .   #FFFFFF   white\n" +
+		"   1:   %   #000000   black\n" +
+		"   2:   r   #FF0000   red\n" +
+		"   3:   X   #00AA00   green\n" +
+		": rows\n1 1r\n1r 1X\n: columns\n1%\n2r\n1X\n"
+	g := checkFormat(t, content, "olsak", "3x2\nC:r;ff0000\nC:a;00aa00\nH:1;1,1r\nH:2;1r,1a\nV:1;1\nV:2;2r\nV:3;1a\n")
+	checkWriteFormat(t, g, "olsak")
+
+	for _, content := range []string{
+		"#d\n   0:   .   #FFFFFF\n   1:   %   #000000\n: rows\n1b\n: columns\n1\n",
+		"#d\n   0:   .   #FFFFFF\n   1:   %   #000000\n: rows\n1.\n: columns\n1\n",
+	} {
+		g = New(SolverOptions{})
+		if err := g.LoadFormat(strings.NewReader(content), "olsak"); !errors.Is(err, ErrUnknownColor) {
+			t.Errorf("expected %v, got %v", ErrUnknownColor, err)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package griddler
+
+import (
+	"errors"
+	"math/rand"
+)
+
+// Constraint selects the puzzles kept by a Generator
+type Constraint int
+
+const (
+	SolvableByLogic Constraint = iota // the line logic alone solves the puzzle
+	UniqueSolution                    // the puzzle has a single solution, counting the solutions being slow on large grids
+	AnyPuzzle                         // every random grid is kept
+)
+
+// defaultGenerateAttempts is the number of random grids tried when not specified
+const defaultGenerateAttempts = 1000
+
+// Generator creates random puzzles meeting a constraint. The TrialTimeout of the
+// solver options bounds the uniqueness check of each grid, a grid whose check
+// times out being rejected: the puzzle generated then depends on the speed of
+// the machine and not only on the seed.
+type Generator struct {
+	Constraint  Constraint
+	Solver      SolverOptions // options of the solver checking the constraint and of the puzzles created
+	MaxAttempts int           // number of random grids tried before giving up, 1000 if 0
+}
+
+// Generate creates a random puzzle solvable by line logic, see Generator
+func Generate(width, height int, density float64, seed int64) (*Griddler, error) {
+	var gen Generator
+	return gen.Generate(width, height, density, seed)
+}
+
+// Generate draws random grids of the given size, each square being filled with
+// the probability density, until the puzzle derived from one of them meets the
+// constraint. The same seed always gives the same puzzle, so that a benchmark
+// corpus can be regenerated. The grid is kept as the goal of the puzzle.
+func (gen *Generator) Generate(width, height int, density float64, seed int64) (*Griddler, error) {
+	if width <= 0 || height <= 0 {
+		return nil, ErrInvalidGridSizeValue
+	}
+	if density < 0 || density > 1 {
+		return nil, ErrInvalidDensity
+	}
+	attempts := gen.MaxAttempts
+	if attempts <= 0 {
+		attempts = defaultGenerateAttempts
+	}
+
+	r := rand.New(rand.NewSource(seed))
+	for ; attempts > 0; attempts-- {
+		cells := make([][]bool, height)
+		for i := range cells {
+			cells[i] = make([]bool, width)
+			for j := range cells[i] {
+				cells[i][j] = r.Float64() < density
+			}
+		}
+		ok, err := gen.accepts(cells)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			g := New(gen.Solver)
+			if err := g.setSolution(cells); err != nil {
+				return nil, err
+			}
+			return g, nil
+		}
+	}
+	return nil, ErrGenerateFailed
+}
+
+// accepts indicates if the puzzle derived from the cells meets the constraint
+func (gen *Generator) accepts(cells [][]bool) (bool, error) {
+	// the checks are not reported to the sink of the options
+	opts := gen.Solver
+	opts.Events = nil
+	if gen.Constraint == AnyPuzzle {
+		return true, nil
+	}
+	// a puzzle solved by logic has a single solution, the slower search
+	// counting the solutions being only needed otherwise
+	undecided, err := undecidedSquares(cells, opts)
+	if err != nil || len(undecided) == 0 || gen.Constraint == SolvableByLogic {
+		return len(undecided) == 0, err
+	}
+	g := New(opts)
+	if err := g.setSolution(cells); err != nil {
+		return false, err
+	}
+	n, err := g.CountSolutions(2)
+	if errors.Is(err, ErrSearchTimedOut) {
+		return false, nil
+	}
+	return n == 1, err
+}
@@ -0,0 +1,64 @@
+package griddler
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestGenerateSeed(t *testing.T) {
+	save := func(seed int64) string {
+		g, err := Generate(12, 10, 0.6, seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var b bytes.Buffer
+		if err := g.Save(&b); err != nil {
+			t.Fatal(err)
+		}
+		return b.String()
+	}
+	first := save(42)
+	if second := save(42); first != second {
+		t.Errorf("the same seed gives different puzzles:\n%s\n%s", first, second)
+	}
+	if other := save(43); first == other {
+		t.Errorf("another seed gives the same puzzle:\n%s", first)
+	}
+}
+
+func TestGenerateConstraint(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		constraint Constraint
+	}{
+		{"logic", SolvableByLogic},
+		{"unique", UniqueSolution},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			gen := Generator{Constraint: tt.constraint}
+			for seed := int64(1); seed <= 5; seed++ {
+				g, err := gen.Generate(8, 8, 0.5, seed)
+				if err != nil {
+					t.Fatal(err)
+				}
+				n, err := g.CountSolutions(2)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if n != 1 {
+					t.Fatalf("seed %d: got %d solutions", seed, n)
+				}
+				res, err := g.Solve()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !res.Solved || tt.constraint == SolvableByLogic && (res.Trials > 0 || res.Probed > 0) {
+					t.Errorf("seed %d: %+v", seed, res)
+				}
+				if sol := g.Solution(); !sol.Equal(g.Goal()) {
+					t.Errorf("seed %d: the solution differs from the goal:\n%s\n%s", seed, sol, g.Goal())
+				}
+			}
+		})
+	}
+}
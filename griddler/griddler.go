@@ -1,42 +1,48 @@
 package griddler
 
 import (
-	"bufio"
-	"container/heap"
+	"bytes"
 	"fmt"
+	"io"
+	"math/bits"
 	"os"
 	"strconv"
 	"strings"
-)
-
-var (
-	UseTrial bool
+	"time"
 )
 
 type Griddler struct {
 	width         int
 	height        int
+	board         []Square // all the squares, row after row
+	palette       []Color
 	lines         [](*Line)
 	columns       [](*Line)
-	lStack        Stack
-	cStack        Stack
+	axes          [][](*Line) // the line families: lines and columns, or the three axes of a triddler
+	isTriddler    bool
+	stacks        []Stack // lines waiting to be solved, one stack per axis
 	solveInitAlgo Algorithm
-	solveAlgos    []Algorithm
+	solveAlgos    []namedAlgorithm
+	opts          SolverOptions
+	phase         string
+	isInit        bool
+	deadline      time.Time
+	goal          *Solution
+	problems      ParseErrors
+	shared        map[*Square][]lineRef // lines containing the squares shared with other griddlers, see Multi
+	found         int                   // number of squares set, to measure the deductions of each algorithm
+	rating        *Rating               // difficulty being measured, nil if not rating
+	exact         exactTables           // tables of solveExact, reused from a line to the next one
+	round         int                   // logic round of the line being solved, see Rating.Rounds
 	//solveQueue    chan (*Square)
 }
 
-func New() *Griddler {
+// New creates an empty griddler which will be solved according to the given options
+func New(opts SolverOptions) *Griddler {
 	g := &Griddler{
-		lStack:        Stack{},
-		cStack:        Stack{},
 		solveInitAlgo: solveInitAlgo,
-		solveAlgos: []Algorithm{
-			solveFilledRanges,
-			solveEmptyRanges,
-			solveAlgo6,
-			solveAlgo7,
-			solveAlgo8,
-		},
+		solveAlgos:    opts.algorithms(),
+		opts:          opts,
 	}
 	return g
 }
@@ -48,6 +54,7 @@ func (g *Griddler) error(e error, l int) error {
 	}
 }
 
+// Load reads the griddler definition from the specified file
 func (g *Griddler) Load(filename string) error {
 	// Loading the specified file
 	gFile, err := os.Open(filename)
@@ -56,481 +63,662 @@ func (g *Griddler) Load(filename string) error {
 	}
 	defer gFile.Close()
 
+	return g.LoadFrom(gFile)
+}
+
+// Parse reads the griddler definition from an in-memory buffer
+func (g *Griddler) Parse(data []byte) error {
+	return g.LoadFrom(bytes.NewReader(data))
+}
+
+// LoadFrom reads the griddler definition from any reader, e.g. an HTTP body or stdin
+func (g *Griddler) LoadFrom(r io.Reader) error {
 	// Reading the griddler size on the first line
-	gScanner := bufio.NewScanner(gFile)
+	gScanner := newScanner(r)
 	gScanner.Scan()
-	firstLine := gScanner.Text()
-
-	// the line should have the format AAAxBBB where AAA is the width and BBB the height
 	line := 1
+	if err := g.parseHeader(gScanner.Text()); err != nil {
+		return g.error(err, line)
+	}
+
+	// Reading the clue lines until the end of the file, the problems are
+	// gathered to be all reported at once
+	for gScanner.Scan() {
+		line++
+		if err := g.parseCluesLine(gScanner.Text(), line); err != nil {
+			g.problems = append(g.problems, &ParseError{line: line, err: err})
+		}
+	}
+	if len(g.problems) > 0 {
+		return g.problems
+	}
+	return nil
+}
+
+// parseHeader reads the size of the griddler and initializes its board: the
+// line should have the format AAAxBBB where AAA is the width and BBB the height,
+// or "triddler N" for a triddler of side N
+func (g *Griddler) parseHeader(firstLine string) error {
+	if strings.HasPrefix(firstLine, triddlerHeader) {
+		side, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(firstLine, triddlerHeader)))
+		if err != nil || side <= 0 {
+			return ErrInvalidGridSizeValue
+		}
+		g.setTriddler(side)
+		return nil
+	}
+
 	firstLineSizes := strings.Split(firstLine, "x")
 	if len(firstLineSizes) != 2 {
-		return g.error(ErrInvalidGridSizeFormat, line)
+		return ErrInvalidGridSizeFormat
 	}
 
 	width, err := strconv.Atoi(firstLineSizes[0])
-	if err != nil {
-		return g.error(ErrInvalidGridSizeValue, line)
+	if err != nil || width <= 0 {
+		return ErrInvalidGridSizeValue
 	}
-	g.width = width
 
 	height, err := strconv.Atoi(firstLineSizes[1])
-	if err != nil {
-		return g.error(ErrInvalidGridSizeValue, line)
+	if err != nil || height <= 0 {
+		return ErrInvalidGridSizeValue
 	}
-	g.height = height
 
 	// and init the board with it
-	g.initBoard()
+	g.setSize(width, height)
+	return nil
+}
 
-	// Reading the clue lines until the end of the file
-	for gScanner.Scan() {
-		line++
-		gLine := gScanner.Text()
-		gLineTokens := strings.Split(gLine, ";")
-		if len(gLineTokens) != 2 {
-			return g.error(ErrMissingSemiColon, line)
-		}
+// parseCluesLine reads a line of the format H:i;c1,c2,... or V:i;c1,c2,... (the
+// axes of a triddler being H, L and R), the clues being possibly followed by
+// the name of their color, or a color definition C:name;rrggbb
+func (g *Griddler) parseCluesLine(gLine string, line int) error {
+	gLineTokens := strings.Split(gLine, ";")
+	if len(gLineTokens) != 2 {
+		return ErrMissingSemiColon
+	}
 
-		gLineInfos := strings.Split(gLineTokens[0], ":")
-		index, err := strconv.Atoi(gLineInfos[1])
+	gLineInfos := strings.Split(gLineTokens[0], ":")
+	if len(gLineInfos) != 2 {
+		return ErrInvalidTokenLine
+	}
+	if gLineInfos[0] == "C" {
+		return g.parseColorLine(gLineInfos[1], gLineTokens[1])
+	}
+	index, err := strconv.Atoi(gLineInfos[1])
+	if err != nil || index <= 0 {
+		return ErrInvalidIntLine
+	}
+
+	// a line without any value has no clue
+	gLineStrings := strings.Split(gLineTokens[1], ",")
+	hidden := strings.TrimSpace(gLineTokens[1]) == hiddenClues
+	if strings.TrimSpace(gLineTokens[1]) == "" || hidden {
+		gLineStrings = nil
+	}
+	gLineNumbers := make([](*Clue), len(gLineStrings))
+	for i, val := range gLineStrings {
+		clue, err := g.parseClue(strings.TrimSpace(val))
 		if err != nil {
-			return g.error(ErrInvalidIntLine, line)
+			return err
 		}
+		gLineNumbers[i] = clue
+	}
 
-		gLineStrings := strings.Split(gLineTokens[1], ",")
-		gLineNumbers := make([](*Clue), len(gLineStrings))
-		for i, val := range gLineStrings {
-			conv, err := strconv.Atoi(val)
-			gLineNumbers[i] = NewClue(conv)
-			if err != nil {
-				return g.error(ErrInvalidIntValue, line)
-			}
+	axis := g.axisIndex(gLineInfos[0])
+	if axis < 0 {
+		return ErrInvalidTokenLine
+	}
+	if index > len(g.axes[axis]) {
+		return ErrTooManyLine
+	}
+	l := g.axes[axis][index-1]
+	if l.src > 0 {
+		return ErrDuplicateLine
+	}
+	l.src = line
+	l.addClues(gLineNumbers)
+	l.unknown = l.unknown || hidden
+	return nil
+}
+
+// parseClue reads a clue length, or "?" if it is unknown, followed by the
+// optional name of its color
+func (g *Griddler) parseClue(val string) (*Clue, error) {
+	var clue *Clue
+	name := strings.TrimLeft(val, "0123456789")
+	switch {
+	case strings.HasPrefix(val, unknownClue):
+		clue = NewUnknownClue()
+		name = strings.TrimPrefix(val, unknownClue)
+	case strings.HasPrefix(val, hiddenClues):
+		return nil, ErrMisplacedHiddenClues
+	default:
+		conv, err := strconv.Atoi(val[:len(val)-len(name)])
+		if err != nil {
+			return nil, ErrInvalidIntValue
 		}
+		clue = NewClue(conv)
+	}
+	color := g.colorIndex(name)
+	if color < 0 {
+		return nil, ErrUnknownColor
+	}
+	clue.color = color
+	return clue, nil
+}
 
-		switch gLineInfos[0] {
-		case "H":
-			if index > height {
-				// TODO error
-			}
-			g.lines[index-1].addClues(gLineNumbers)
-		case "V":
-			if index > width {
-				// TODO error
-			}
-			g.columns[index-1].addClues(gLineNumbers)
-		default:
-			return g.error(ErrInvalidTokenLine, line)
+// Save writes the griddler definition in the native format read by LoadFrom: the
+// size line followed by the clues of every line then of every column (or of
+// every axis of a triddler), in order
+func (g *Griddler) Save(w io.Writer) error {
+	var b bytes.Buffer
+	if g.isTriddler {
+		fmt.Fprintf(&b, "%s%d\n", triddlerHeader, g.height)
+	} else {
+		fmt.Fprintf(&b, "%dx%d\n", g.width, g.height)
+	}
+	for _, c := range g.palette[defaultColor+1:] {
+		fmt.Fprintf(&b, "C:%s;%s\n", c.Name, hexRGB(c.RGB))
+	}
+	tokens := g.axisTokens()
+	for k, ls := range g.axes {
+		for i, l := range ls {
+			fmt.Fprintf(&b, "%s:%d;%s\n", tokens[k], i+1, l.cluesString())
 		}
 	}
-	return nil
+	_, err := w.Write(b.Bytes())
+	return err
 }
 
+// Show prints the current state of the board on the standard output
 func (g *Griddler) Show() {
-	g.showColumnHeader()
-	g.showBody()
-	g.showColumnFooter()
+	g.Print(os.Stdout)
 }
 
-func (g *Griddler) showColumnHeader() {
-	fmt.Printf("    ")
-	for i := 0; i < g.width; i++ {
-		fmt.Printf("%d", (i+1)/10)
-	}
-	fmt.Println()
-	fmt.Printf("    ")
-	for i := 0; i < g.width; i++ {
-		fmt.Printf("%d", (i+1)%10)
+// Print writes the current state of the board to w
+func (g *Griddler) Print(w io.Writer) {
+	if g.isTriddler {
+		g.printTriddler(w)
+		return
 	}
-	fmt.Println()
-	fmt.Printf("   +")
-	for i := 0; i < g.width; i++ {
-		fmt.Printf("-")
+	g.showColumnHeader(w)
+	g.showBody(w)
+	g.showColumnFooter(w)
+}
+
+// labelWidth returns the number of digits needed to display the indexes up to n,
+// at least two to keep the layout of the small griddlers
+func labelWidth(n int) int {
+	return max(2, len(strconv.Itoa(n)))
+}
+
+// showColumnNumbers writes the column indexes vertically, one row per digit
+func (g *Griddler) showColumnNumbers(w io.Writer) {
+	margin := strings.Repeat(" ", labelWidth(g.height)+2)
+	for d := labelWidth(g.width) - 1; d >= 0; d-- {
+		div := 1
+		for k := 0; k < d; k++ {
+			div *= 10
+		}
+		fmt.Fprint(w, margin)
+		for i := 0; i < g.width; i++ {
+			fmt.Fprintf(w, "%d", (i+1)/div%10)
+		}
+		fmt.Fprintln(w)
 	}
-	fmt.Println("+")
 }
 
-func (g *Griddler) showBody() {
+func (g *Griddler) showBorder(w io.Writer) {
+	fmt.Fprintf(w, "%s+%s+\n", strings.Repeat(" ", labelWidth(g.height)+1), strings.Repeat("-", g.width))
+}
+
+func (g *Griddler) showColumnHeader(w io.Writer) {
+	g.showColumnNumbers(w)
+	g.showBorder(w)
+}
+
+func (g *Griddler) showBody(w io.Writer) {
+	lw := labelWidth(g.height)
 	for i := 0; i < g.height; i++ {
-		fmt.Printf("%2d |", i+1)
+		fmt.Fprintf(w, "%*d |", lw, i+1)
 		for j := 0; j < g.width; j++ {
-			g.lines[i].squares[j].show()
+			g.showSquare(w, g.lines[i].squares[j])
 		}
-		fmt.Printf("| %-2d", i+1)
+		fmt.Fprintf(w, "| %-*d", lw, i+1)
 		if g.lines[i].isDone {
-			fmt.Printf(" D")
+			fmt.Fprintf(w, " D")
 		}
-		fmt.Println()
+		fmt.Fprintln(w)
 	}
 }
 
-func (g *Griddler) showColumnFooter() {
-	fmt.Printf("   +")
-	for i := 0; i < g.width; i++ {
-		fmt.Printf("-")
-	}
-	fmt.Println("+")
-	fmt.Printf("    ")
-	for i := 0; i < g.width; i++ {
-		fmt.Printf("%d", (i+1)/10)
-	}
-	fmt.Println()
-	fmt.Printf("    ")
-	for i := 0; i < g.width; i++ {
-		fmt.Printf("%d", (i+1)%10)
+// showSquare writes the square, a colored one being shown by its color name
+func (g *Griddler) showSquare(w io.Writer, s *Square) {
+	if s.value == FILLED && s.color != defaultColor {
+		fmt.Fprint(w, g.palette[s.color].Name)
+		return
 	}
-	fmt.Println()
-	fmt.Println()
-	fmt.Printf("    ")
+	s.show(w)
+}
+
+func (g *Griddler) showColumnFooter(w io.Writer) {
+	g.showBorder(w)
+	g.showColumnNumbers(w)
+	fmt.Fprintln(w)
+	fmt.Fprint(w, strings.Repeat(" ", labelWidth(g.height)+2))
 	for i := 0; i < g.width; i++ {
 		if g.columns[i].isDone {
-			fmt.Printf("D")
+			fmt.Fprintf(w, "D")
 		} else {
-			fmt.Printf(" ")
+			fmt.Fprintf(w, " ")
 		}
 	}
-	fmt.Println()
+	fmt.Fprintln(w)
+}
+
+// setSize defines the dimensions of the griddler and initializes its board
+func (g *Griddler) setSize(width, height int) {
+	g.width = width
+	g.height = height
+	g.problems = nil
+	g.palette = defaultPalette()
+	g.isTriddler = false
+	g.stacks = make([]Stack, 2)
+	g.initBoard()
+	g.setAxes([][](*Line){g.lines, g.columns})
+}
+
+// setAxes defines the line families of the griddler, the first one being its
+// lines and, for a rectangular griddler, the second one its columns
+func (g *Griddler) setAxes(axes [][](*Line)) {
+	g.axes = axes
+	for k, ls := range axes {
+		for _, l := range ls {
+			l.axis = k
+		}
+	}
+	g.lines = axes[0]
+	g.columns = nil
+	if !g.isTriddler {
+		g.columns = axes[1]
+	}
 }
 
+// initBoard allocates all the squares at once in the board, the lines and the
+// columns only referencing them
 func (g *Griddler) initBoard() {
+	g.board = make([]Square, g.width*g.height)
+	for i := range g.board {
+		g.board[i] = Square{Tile: Tile{EMPTY}, x: i / g.width, y: i % g.width}
+	}
 	g.lines = make([](*Line), g.height)
 	for i := 0; i < g.height; i++ {
 		g.lines[i] = NewLine(g, i, g.width)
 		for j := 0; j < g.width; j++ {
-			g.lines[i].squares[j] = NewSquare(i, j, EMPTY)
+			g.lines[i].squares[j] = g.square(i, j)
 		}
 	}
 	g.columns = make([](*Line), g.width)
 	for i := 0; i < g.width; i++ {
 		g.columns[i] = NewLine(g, i, g.height)
 		for j := 0; j < g.height; j++ {
-			g.columns[i].squares[j] = g.lines[j].squares[i]
+			g.columns[i].squares[j] = g.square(j, i)
 		}
 	}
 	//g.solveQueue = make(chan (*Square), g.width*g.height)
 }
 
+// square returns the square at the given line and column
+func (g *Griddler) square(x, y int) *Square {
+	return &g.board[x*g.width+y]
+}
+
+// save copies the solving state of the griddler: the board values and, for
+// each line, its counters and clue limits. The saved lines do not reference
+// any square, the values being restored from the copied board.
 func (g *Griddler) save() *Griddler {
 	result := *g
-
-	result.lines = make([](*Line), g.height)
-	for i := 0; i < g.height; i++ {
-		result.lines[i] = NewLine(&result, i, g.width)
-		result.lines[i].sumBlanks = g.lines[i].sumBlanks
-		result.lines[i].sumClues = g.lines[i].sumClues
-		result.lines[i].totalClues = g.lines[i].totalClues
-		result.lines[i].cb = g.lines[i].cb
-		result.lines[i].ce = g.lines[i].ce
-		result.lines[i].isDone = g.lines[i].isDone
-		for j := 0; j < g.width; j++ {
-			result.lines[i].squares[j] = NewSquare(i, j, g.lines[i].squares[j].value)
-		}
-		result.lines[i].clues = make([](*Clue), len(g.lines[i].clues))
-		for j := 0; j < len(g.lines[i].clues); j++ {
-			result.lines[i].clues[j] = NewClue(g.lines[i].clues[j].length)
-			result.lines[i].clues[j].l = result.lines[i]
-			result.lines[i].clues[j].index = j
-			result.lines[i].clues[j].begin = g.lines[i].clues[j].begin
-			result.lines[i].clues[j].end = g.lines[i].clues[j].end
+	result.board = make([]Square, len(g.board))
+	copy(result.board, g.board)
+
+	axes := make([][](*Line), len(g.axes))
+	for k, ls := range g.axes {
+		axes[k] = make([](*Line), len(ls))
+		for i, l := range ls {
+			axes[k][i] = l.save(&result)
 		}
 	}
-
-	result.columns = make([](*Line), g.width)
-	for i := 0; i < g.width; i++ {
-		result.columns[i] = NewLine(&result, i, g.height)
-		result.columns[i].sumBlanks = g.columns[i].sumBlanks
-		result.columns[i].sumClues = g.columns[i].sumClues
-		result.columns[i].totalClues = g.columns[i].totalClues
-		result.columns[i].cb = g.columns[i].cb
-		result.columns[i].ce = g.columns[i].ce
-		result.columns[i].isDone = g.columns[i].isDone
-		for j := 0; j < g.height; j++ {
-			result.columns[i].squares[j] = result.lines[j].squares[i]
-		}
-		result.columns[i].clues = make([](*Clue), len(g.columns[i].clues))
-		for j := 0; j < len(g.columns[i].clues); j++ {
-			result.columns[i].clues[j] = NewClue(g.columns[i].clues[j].length)
-			result.columns[i].clues[j].l = result.columns[i]
-			result.columns[i].clues[j].index = j
-			result.columns[i].clues[j].begin = g.columns[i].clues[j].begin
-			result.columns[i].clues[j].end = g.columns[i].clues[j].end
-		}
-	}
-
+	result.setAxes(axes)
 	return &result
 }
 
 func (g *Griddler) restore(clone *Griddler) {
-	for i := 0; i < clone.height; i++ {
-		g.lines[i].sumBlanks = clone.lines[i].sumBlanks
-		g.lines[i].sumClues = clone.lines[i].sumClues
-		g.lines[i].totalClues = clone.lines[i].totalClues
-		g.lines[i].cb = clone.lines[i].cb
-		g.lines[i].ce = clone.lines[i].ce
-		g.lines[i].isDone = clone.lines[i].isDone
-		for j := 0; j < clone.width; j++ {
-			g.lines[i].squares[j].value = clone.lines[i].squares[j].value
-		}
-		for j := 0; j < len(clone.lines[i].clues); j++ {
-			g.lines[i].clues[j].begin = clone.lines[i].clues[j].begin
-			g.lines[i].clues[j].end = clone.lines[i].clues[j].end
+	copy(g.board, clone.board)
+	for k, ls := range clone.axes {
+		for i, l := range ls {
+			g.axes[k][i].restore(l)
 		}
 	}
+}
 
-	for i := 0; i < clone.width; i++ {
-		g.columns[i].sumBlanks = clone.columns[i].sumBlanks
-		g.columns[i].sumClues = clone.columns[i].sumClues
-		g.columns[i].totalClues = clone.columns[i].totalClues
-		g.columns[i].cb = clone.columns[i].cb
-		g.columns[i].ce = clone.columns[i].ce
-		g.columns[i].isDone = clone.columns[i].isDone
-		for j := 0; j < clone.height; j++ {
-			g.columns[i].squares[j] = g.lines[j].squares[i]
-		}
-		for j := 0; j < len(clone.columns[i].clues); j++ {
-			g.columns[i].clues[j].begin = clone.columns[i].clues[j].begin
-			g.columns[i].clues[j].end = clone.columns[i].clues[j].end
+// keep saves the whole state of the griddler, its board as well as its solving
+// progress and its waiting lines, and returns the function putting it back
+func (g *Griddler) keep() func() {
+	saved, isInit, found, phase := g.save(), g.isInit, g.found, g.phase
+	stacks := make([]Stack, len(g.stacks))
+	for k, st := range g.stacks {
+		stacks[k] = append(Stack{}, st...)
+	}
+	return func() {
+		g.restore(saved)
+		g.isInit, g.found, g.phase = isInit, found, phase
+		for k := range g.stacks {
+			for g.stacks[k].pop() != nil {
+			}
+			for _, l := range stacks[k] {
+				g.stacks[k].push(l)
+			}
 		}
 	}
 }
 
-func (g *Griddler) Solve() bool {
-	g.solveInit()
-	nbTrial, nbTrialSuccess := 0, 0
-
-	for {
-		fmt.Println("\nSolving")
-		g.solveByLogic()
+// Result gathers the outcome of a solving session
+type Result struct {
+	Solved        bool // all squares of the griddler have been found
+	Trials        int  // number of trial attempts performed
+	TrialsSuccess int  // number of trial attempts which led to a contradiction
+	TimedOut      bool // the trial&error phase has been interrupted by the timeout
+	Probed        int  // number of squares found by the two-sided probing
+}
 
-		if !g.isDone() && UseTrial {
-			saved := g.save()
+// Solve tries to complete the griddler, an error wrapping a SolveError is returned
+// if the puzzle turns out to be contradictory
+func (g *Griddler) Solve() (Result, error) {
+	res := Result{}
+	if err := g.opts.check(); err != nil {
+		return res, err
+	}
+	if err := g.Validate(); err != nil {
+		return res, err
+	}
+	g.setDeadline()
+	if err := g.solveInit(); err != nil {
+		g.emit(Event{Kind: Contradiction, Err: err})
+		return res, err
+	}
 
-			pq := make(prioQueue, 0)
-			selected, potential, total := g.populateForTrial(&pq)
-			fmt.Printf("Entering Trial&Error phase: %d / %d / %d\n", selected, potential, total)
+	for {
+		g.phase = PhaseLogic
+		g.emit(Event{Kind: PhaseStart})
+		if err := g.solveByLogic(); err != nil {
+			g.emit(Event{Kind: Contradiction, Err: err})
+			return res, err
+		}
 
-			hasError := false
-			attempt := 1
-			for !hasError {
-				if pq.Len() == 0 {
-					break
-				}
-				s := heap.Pop(&pq).(*PrioSquare)
-				fmt.Printf("\rAttempt %3d / %3d", attempt, selected)
-				g.SetValue(g.lines[s.x].squares[s.y], s.pvalue)
-				hasError = g.solveByTrial()
-				nbTrial++
-				if g.isDone() {
-					break
-				}
-				g.restore(saved)
-				if hasError {
-					fmt.Printf("\nFOUND (%d,%d)\n", s.x+1, s.y+1)
-					nbTrialSuccess++
-					if s.pvalue == FILLED {
-						g.SetValue(g.lines[s.x].squares[s.y], BLANK)
-					} else {
-						g.SetValue(g.lines[s.x].squares[s.y], FILLED)
-					}
-					g.Show()
-					break
-				}
-				attempt++
+		if !g.isDone() && g.opts.UseTrial {
+			found, err := g.solveTrialRound(&res)
+			if err != nil {
+				g.emit(Event{Kind: Contradiction, Err: err})
+				return res, err
+			}
+			// no contradiction found, another round would not progress
+			if !found {
+				break
 			}
 		} else {
 			break
 		}
 	}
 
-	g.Show()
-	fmt.Printf("\nTotal trial attempts: %d/%d\n", nbTrialSuccess, nbTrial)
-	return g.isDone()
+	res.Solved = g.isDone()
+	g.emit(Event{Kind: SolveEnd, Result: &res})
+	return res, nil
 }
 
-func (g *Griddler) solveInit() {
-	for _, line := range g.lines {
-		g.solveInitAlgo(g, line)
+func (g *Griddler) solveInit() (err error) {
+	// the clue limits must not be reset once the solving has started
+	if g.isInit {
+		return
 	}
-	for _, col := range g.columns {
-		g.solveInitAlgo(g, col)
+	defer recoverSolveError(&err)
+	for _, ls := range g.axes {
+		for _, l := range ls {
+			found := g.found
+			g.solveInitAlgo(g, l)
+			g.record(initDeduction, found)
+		}
 	}
+	g.isInit = true
+	return
 }
 
-func (g *Griddler) solveByLogic() {
-	defer func() {
-		if r := recover(); r != nil {
-			if serr, ok := r.(*SolveError); ok {
-				fmt.Printf("%v\n", serr)
-				fmt.Printf("Please verify your input file...\n")
-				os.Exit(1)
-			} else {
-				panic(r)
+func (g *Griddler) solveByLogic() (err error) {
+	defer recoverSolveError(&err)
+	g.solveGeneric()
+	g.verify()
+	return
+}
+
+// verify checks that a completed board matches all the clues, the algorithms
+// being able to complete a line without detecting that it is contradictory
+func (g *Griddler) verify() {
+	if !g.isDone() {
+		return
+	}
+	for _, ls := range g.axes {
+		for _, l := range ls {
+			if !l.matchesClues() {
+				panic(&SolveError{l: l, err: ErrInvalidSolution})
 			}
 		}
-	}()
-	g.solveGeneric()
+	}
 }
 
-func (g *Griddler) populateForTrial(pq *prioQueue) (selected int, potential int, total int) {
-	for i := 0; i < g.height; i++ {
-		for j := 0; j < g.width; j++ {
-			total++
-			if g.lines[i].squares[j].value == EMPTY {
-				potential++
-				// to assign a higher priority, we check for borders and neighbours
-				priority := 0
-				pvalue := FILLED
-				if i == 0 || i == g.height-1 {
-					priority++
-				}
-				if j == 0 || j == g.width-1 {
-					priority++
-				}
-				if i > 0 && g.lines[i-1].squares[j].value != EMPTY {
-					if g.lines[i-1].squares[j].value == FILLED {
-						pvalue = BLANK
-					}
-					priority++
-				}
-				if i < g.height-1 && g.lines[i+1].squares[j].value != EMPTY {
-					if g.lines[i+1].squares[j].value == FILLED {
-						pvalue = BLANK
-					}
-					priority++
-				}
-				if j > 0 && g.lines[i].squares[j-1].value != EMPTY {
-					if g.lines[i].squares[j-1].value == FILLED {
-						pvalue = BLANK
-					}
-					priority++
-				}
-				if j < g.width-1 && g.lines[i].squares[j+1].value != EMPTY {
-					if g.lines[i].squares[j+1].value == FILLED {
-						pvalue = BLANK
-					}
-					priority++
+// solveGeneric solves the lines waiting in the stacks, taking one line of each
+// axis in turn, until no more deduction can be made. The fallback algorithms
+// only run once the stacks are empty, on the lines changed since they last
+// did, the lines where they find squares being then solved again.
+func (g *Griddler) solveGeneric() {
+	ls := make([](*Line), len(g.stacks))
+	for {
+		for g.popLines(ls) {
+			for _, l := range ls {
+				if l != nil && !l.isDone {
+					//fmt.Printf("\n=================== checking %s %d ===================\n", l.kind(), l.index+1)
+					g.solveLine(l, false)
 				}
-				// we only add those
-				if priority > 0 {
-					selected++
-					heap.Push(pq, &PrioSquare{g.lines[i].squares[j], pvalue, priority})
+			}
+		}
+		for _, ls := range g.axes {
+			for _, l := range ls {
+				if l.isChanged && !l.isDone {
+					l.isChanged = false
+					g.solveLine(l, true)
 				}
 			}
 		}
+		if !g.isStacked() {
+			break
+		}
 	}
-	return
+	g.round = 0
 }
 
-// TODO add a parameter to indicate the depth of the trial
-func (g *Griddler) solveByTrial() (hasError bool) {
-	defer func() {
-		if r := recover(); r != nil {
-			if _, ok := r.(*SolveError); ok {
-				hasError = true
-			} else {
-				panic(r)
-			}
-		}
-	}()
-	g.solveGeneric()
+// popLines takes the next line of each stack, nil if it is empty, and returns
+// false when all the stacks are empty
+func (g *Griddler) popLines(ls [](*Line)) bool {
+	found := false
+	for k := range g.stacks {
+		ls[k] = g.stacks[k].pop()
+		found = found || ls[k] != nil
+	}
+	return found
+}
 
-	return
+// isStacked indicates if a line is waiting to be solved
+func (g *Griddler) isStacked() bool {
+	for _, st := range g.stacks {
+		if len(st) > 0 {
+			return true
+		}
+	}
+	return false
 }
 
-func (g *Griddler) solveGeneric() {
-	var l, c *Line
-	if ok := g.lStack.pop(); ok != nil {
-		l = ok.(*Line)
-	} else {
-		l = nil
+// solveLine applies the line algorithms, the fallback ones or the others, on
+// the line until it is done
+func (g *Griddler) solveLine(l *Line, fallback bool) {
+	g.round = l.round
+	if g.rating != nil && g.phase != PhaseTrial {
+		g.rating.Rounds = max(g.rating.Rounds, l.round)
 	}
-	if ok := g.cStack.pop(); ok != nil {
-		c = ok.(*Line)
-	} else {
-		c = nil
+	found := g.found
+	if g.completeLine(l) {
+		g.record(lineDeduction, found)
+		return
 	}
 
-	for l != nil || c != nil {
-		if l != nil && !l.isDone {
-			//fmt.Printf("\n=================== checking line %d ===================\n", l.index+1)
-			g.solveLine(l)
-		}
-		if c != nil && !c.isDone {
-			//fmt.Printf("\n=================== checking column %d ===================\n", c.index+1)
-			g.solveLine(c)
+	for _, na := range g.solveAlgos {
+		if g.isColored() && !na.colored || l.unknown && !na.unknown || na.fallback != fallback {
+			continue
 		}
-		if ok := g.lStack.pop(); ok != nil {
-			l = ok.(*Line)
-		} else {
-			l = nil
-		}
-		if ok := g.cStack.pop(); ok != nil {
-			c = ok.(*Line)
+		if !l.isDone {
+			found = g.found
+			na.algo(g, l)
+			g.record(na.name, found)
 		} else {
-			c = nil
+			return
 		}
 	}
 }
 
-func (g *Griddler) solveLine(l *Line) {
-	// if we found all clues, we can blank all remaining square
-	if l.sumClues == l.totalClues {
+// completeLine sets the remaining squares of the line once all its filled
+// squares or all its blanks are found, and indicates if it did
+func (g *Griddler) completeLine(l *Line) bool {
+	// if we found all clues, we can blank all remaining square, as long as
+	// their total is known
+	if !l.unknown && l.sumClues == l.totalClues {
 		for _, s := range l.squares {
 			if s.value == EMPTY {
 				g.SetValue(s, BLANK)
 			}
 		}
-		return
+		return true
 	}
-	// if we found all blanks, we can set the remaining clues
-	if l.sumBlanks == l.length-l.totalClues {
+	// if we found all blanks, we can set the remaining clues, as long as
+	// their color and their total are known
+	if !g.isColored() && !l.unknown && l.sumBlanks == l.length-l.totalClues {
 		for _, s := range l.squares {
 			if s.value == EMPTY {
 				g.SetValue(s, FILLED)
 			}
 		}
-		return
+		return true
 	}
+	return false
+}
 
-	for _, algo := range g.solveAlgos {
-		if !l.isDone {
-			algo(g, l)
-		} else {
-			return
-		}
+// SetValue sets the square as blank or filled with the default color
+func (g *Griddler) SetValue(s *Square, value int) {
+	if value == FILLED {
+		g.SetColor(s, defaultColor)
+	} else {
+		g.SetColor(s, backgroundColor)
 	}
 }
 
-func (g *Griddler) SetValue(s *Square, value int) {
+// SetColor sets the square to the given palette index, the background meaning
+// a blank square
+func (g *Griddler) SetColor(s *Square, color int) {
 	switch {
 	case s.value == EMPTY:
+		if !s.canBe(color) {
+			panic(&SolveError{s: s, err: ErrOverridingValue})
+		}
+		value := valueOf(color)
 		s.value = value
-		g.lStack.push(g.lines[s.x])
-		g.cStack.push(g.columns[s.y])
-		if value == FILLED {
-			g.lines[s.x].incrementClues()
-			g.columns[s.y].incrementClues()
-		} else {
-			g.lines[s.x].incrementBlanks()
-			g.columns[s.y].incrementBlanks()
+		s.color = color
+		s.colors = 0
+		g.found++
+		g.pushLines(s)
+		for _, ref := range g.linesOf(s) {
+			if value == FILLED {
+				ref.l.incrementClues()
+			} else {
+				ref.l.incrementBlanks()
+			}
 		}
-		//fmt.Printf("FOUND (%d,%d)\n", s.x+1, s.y+1)
+		g.emit(Event{Kind: SquareDeduced, Line: s.x + 1, Column: s.y + 1, Value: value, Color: color})
 		//g.solveQueue <- s
 		//g.Show()
-	case s.value != value:
-		panic(&SolveError{s, ErrOverridingValue})
+	case s.color != color:
+		panic(&SolveError{s: s, err: ErrOverridingValue})
 	}
 }
 
-func (g *Griddler) isDone() bool {
-	for _, l := range g.lines {
-		if !l.isDone {
-			return false
-		}
+// restrict reduces the palette indexes still possible for the square to the
+// given mask, the square being set as soon as a single one remains
+func (g *Griddler) restrict(s *Square, mask uint64) {
+	current := g.possible(s)
+	next := current & mask
+	switch {
+	case next == 0:
+		panic(&SolveError{s: s, err: ErrOverridingValue})
+	case next == current:
+	case next&(next-1) == 0:
+		g.SetColor(s, bits.TrailingZeros64(next))
+	default:
+		s.colors = next
+		g.pushLines(s)
 	}
-	for _, c := range g.columns {
-		if !c.isDone {
-			return false
+}
+
+// exclude removes the given palette index from the ones possible for the square
+func (g *Griddler) exclude(s *Square, color int) {
+	g.restrict(s, ^(uint64(1) << uint(color)))
+}
+
+// pushLines schedules the lines containing the square to be solved again
+func (g *Griddler) pushLines(s *Square) {
+	for _, ref := range g.linesOf(s) {
+		g.pushLine(ref.l)
+	}
+}
+
+// pushLine schedules the line to be solved in the round following the one of
+// the line being solved, see Rating.Rounds
+func (g *Griddler) pushLine(l *Line) {
+	if !l.isStacked {
+		l.round = g.round + 1
+	}
+	l.isChanged = true
+	l.g.stacks[l.axis].push(l)
+}
+
+// lineRef locates a square in one of its lines
+type lineRef struct {
+	l *Line
+	i int // index of the square in the line
+}
+
+// linesOf returns the line of each axis containing the square, and those of the
+// other griddlers sharing it within a multi-griddler
+func (g *Griddler) linesOf(s *Square) []lineRef {
+	if refs, ok := g.shared[s]; ok {
+		return refs
+	}
+	if g.isTriddler {
+		return g.triddlerLinesOf(s)
+	}
+	return []lineRef{{g.lines[s.x], s.y}, {g.columns[s.y], s.x}}
+}
+
+func (g *Griddler) isDone() bool {
+	for _, ls := range g.axes {
+		for _, l := range ls {
+			if !l.isDone {
+				return false
+			}
 		}
 	}
 	return true
@@ -0,0 +1,107 @@
+package griddler
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveParseRoundTrip(t *testing.T) {
+	defs := map[string]string{
+		"grid":     "3x2\nH:1;1,1\nH:2;3\nV:1;2\nV:2;1\nV:3;2\n",
+		"empty":    "2x2\nH:1;0\nH:2;2\nV:1;1\nV:2;1\n",
+		"colored":  "2x2\nC:r;ff0000\nC:g;00aa00\nH:1;1,1r\nH:2;1g,1\nV:1;1,1g\nV:2;1r,1\n",
+		"unknown":  "3x3\nH:1;?\nH:2;*\nH:3;1,?\nV:1;2\nV:2;?\nV:3;1,1\n",
+		"triddler": "triddler 2\nH:1;1\nH:2;3\nL:1;2\nL:2;1\nR:1;2\nR:2;1\n",
+	}
+	files, err := filepath.Glob("../data/*.grid*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defs[filepath.Base(f)] = string(data)
+	}
+
+	for name, def := range defs {
+		t.Run(name, func(t *testing.T) {
+			g := New(SolverOptions{})
+			if err := g.Parse([]byte(def)); err != nil {
+				t.Fatal(err)
+			}
+			var first bytes.Buffer
+			if err := g.Save(&first); err != nil {
+				t.Fatal(err)
+			}
+			parsed := New(SolverOptions{})
+			if err := parsed.Parse(first.Bytes()); err != nil {
+				t.Fatalf("%v\n%s", err, first.String())
+			}
+			var second bytes.Buffer
+			if err := parsed.Save(&second); err != nil {
+				t.Fatal(err)
+			}
+			if first.String() != second.String() {
+				t.Errorf("round trip differs:\n%s\n%s", first.String(), second.String())
+			}
+		})
+	}
+}
+
+func TestUndefinedLines(t *testing.T) {
+	// the lines never defined have no clues
+	g := New(SolverOptions{})
+	if err := g.Parse([]byte("3x2\nH:1;1,1\nV:1;1\nV:3;1\n")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.Solve(); err != nil {
+		t.Fatal(err)
+	}
+	for i, state := range []int{FILLED, BLANK, FILLED, BLANK, BLANK, BLANK} {
+		if s := g.lines[i/3].squares[i%3]; s.value != state {
+			t.Errorf("square %d,%d is %d, expected %d", i/3+1, i%3+1, s.value, state)
+		}
+	}
+
+	// while a line info without index is a problem
+	for _, def := range []string{"1x1\nH:;1\nV:1;1\n", "1x1\nH1;1\nV:1;1\n", "1x1\n:1;1\nV:1;1\n"} {
+		g := New(SolverOptions{})
+		var perrs ParseErrors
+		if err := g.Parse([]byte(def)); !errors.As(err, &perrs) {
+			t.Errorf("%q: expected a parse error, got %v", def, err)
+		}
+	}
+}
+
+// benchmarkSolve solves a random puzzle of the given size, the puzzles with a
+// high density being mostly solved by line logic
+func benchmarkSolve(b *testing.B, size int, density float64) {
+	r := rand.New(rand.NewSource(1))
+	cells := make([][]bool, size)
+	for i := range cells {
+		cells[i] = make([]bool, size)
+		for j := range cells[i] {
+			cells[i][j] = r.Float64() < density
+		}
+	}
+	for b.Loop() {
+		g, err := FromSolution(cells)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := g.Solve(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSolve100(b *testing.B)  { benchmarkSolve(b, 100, 0.9) }
+func BenchmarkSolve400(b *testing.B)  { benchmarkSolve(b, 400, 0.9) }
+func BenchmarkSolve1000(b *testing.B) { benchmarkSolve(b, 1000, 0.9) }
+func BenchmarkSolve2000(b *testing.B) { benchmarkSolve(b, 2000, 0.9) }
@@ -0,0 +1,256 @@
+package griddler
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Hint is a single deduction made on a line of a griddler being played, see
+// Griddler.Hint
+type Hint struct {
+	Kind      string // name of the line family, e.g. "line" or "column"
+	Index     int    // 1-based index of the line in its family
+	Squares   []int  // 1-based positions in the line of the squares found
+	Colors    []int  // palette index found for each square, the background for a blank one
+	Algorithm string // name of the deduction, as in Rating.Deductions
+	Technique string // name of the deduction for a human solver, e.g. "overlap"
+	Reason    string // justification of the deduction
+}
+
+// String returns the hint as displayed to the player, e.g. "line 5: clue 3 of
+// length 6 can only fit in columns 7–12, so columns 10–12 are filled (overlap)"
+func (h *Hint) String() string {
+	return fmt.Sprintf("%s %d: %s (%s)", h.Kind, h.Index, h.Reason, h.Technique)
+}
+
+// hintTechniques names the deductions as a human solver would, along with the
+// reason given when the limits of the clues are not enough to explain them
+var hintTechniques = map[string]struct{ name, reason string }{
+	initDeduction:       {"overlap", "the clues overlap whatever their position"},
+	lineDeduction:       {"completion", ""},
+	"solveFilledRanges": {"filled ranges", "the filled squares of the line pin down the clues around them"},
+	"solveEmptyRanges":  {"empty ranges", "the space between the blanks is too small for the clues able to reach it"},
+	"solveAlgo6":        {"unreachable squares", "no clue able to extend the filled squares nearby reaches that far"},
+	"solveAlgo7":        {"minimal length", "every clue able to cover the filled squares nearby is too long to stop at the blank"},
+	"solveAlgo8":        {"border", "the clue at the border of the line can not reach there without touching the filled squares beyond"},
+	"solveExact":        {"all placements", "every placement of the clues compatible with the line agrees there"},
+}
+
+// Hint returns the next deduction a human solver could make on the griddler,
+// typically loaded from a state file: the line algorithms are tried from the
+// simplest to the most elaborate one, each of them on every line, and the
+// squares found by the first success are returned. The griddler is left
+// unchanged. ErrNoHint is returned when no line gives any information anymore,
+// and an error wrapping a SolveError when the squares already set contradict
+// the clues.
+func (g *Griddler) Hint() (h *Hint, err error) {
+	if g.shared != nil {
+		return nil, ErrUnsupportedPuzzle
+	}
+	if err := g.opts.check(); err != nil {
+		return nil, err
+	}
+	if err := g.Validate(); err != nil {
+		return nil, err
+	}
+
+	// the deductions are made on the griddler itself, then undone along with
+	// the counters and the lines waiting to be solved
+	restore, events := g.keep(), g.opts.Events
+	g.opts.Events = nil
+	defer func() {
+		restore()
+		g.opts.Events = events
+	}()
+	defer recoverSolveError(&err)
+
+	if !g.isInit {
+		for _, ls := range g.axes {
+			for _, l := range ls {
+				if h := g.tryDeduction(l, initDeduction, func() { g.solveInitAlgo(g, l) }); h != nil {
+					return h, nil
+				}
+			}
+		}
+		g.isInit = true
+	}
+
+	// the algorithms finding nothing may still reduce the clue limits, the
+	// search goes on as long as they do
+	for {
+		limits := g.limitsKey()
+		if h := g.tryAxes(lineDeduction, func(l *Line) { g.completeLine(l) }); h != nil {
+			return h, nil
+		}
+		for _, na := range g.solveAlgos {
+			if g.isColored() && !na.colored {
+				continue
+			}
+			if h := g.tryAxes(na.name, func(l *Line) {
+				if !l.unknown || na.unknown {
+					na.algo(g, l)
+				}
+			}); h != nil {
+				return h, nil
+			}
+		}
+		if g.limitsKey() == limits {
+			return nil, ErrNoHint
+		}
+	}
+}
+
+// tryAxes applies the deduction on every unsolved line, until one of them
+// gives some squares
+func (g *Griddler) tryAxes(name string, solve func(l *Line)) *Hint {
+	for _, ls := range g.axes {
+		for _, l := range ls {
+			if l.isDone {
+				continue
+			}
+			if h := g.tryDeduction(l, name, func() { solve(l) }); h != nil {
+				return h
+			}
+		}
+	}
+	return nil
+}
+
+// tryDeduction applies the deduction on the line, and returns the squares it
+// found as a hint, nil if none
+func (g *Griddler) tryDeduction(l *Line, name string, solve func()) *Hint {
+	before := make([]int, l.length)
+	for i, s := range l.squares {
+		before[i] = s.state()
+	}
+	solve()
+
+	h := &Hint{
+		Kind:      l.kind(),
+		Index:     l.index + 1,
+		Algorithm: name,
+		Technique: hintTechniques[name].name,
+	}
+	for i, s := range l.squares {
+		if before[i] < 0 && s.value != EMPTY {
+			h.Squares = append(h.Squares, i+1)
+			h.Colors = append(h.Colors, s.color)
+		}
+	}
+	if len(h.Squares) == 0 {
+		return nil
+	}
+	h.Reason = g.explain(l, h)
+	return h
+}
+
+// explain justifies the squares of the hint, run by run, from the limits of
+// the clues reached by the deduction
+func (g *Griddler) explain(l *Line, h *Hint) string {
+	reasons := make([]string, 0)
+	for k := 0; k < len(h.Squares); {
+		// the run gathers the consecutive squares of the same color
+		color, first, last := h.Colors[k], h.Squares[k]-1, h.Squares[k]-1
+		for k++; k < len(h.Squares) && h.Squares[k]-1 == last+1 && h.Colors[k] == color; k++ {
+			last++
+		}
+		subject, verb := l.squaresText(first, last), "is"
+		if last > first {
+			verb = "are"
+		}
+
+		var reason string
+		switch {
+		case h.Algorithm == lineDeduction && color == backgroundColor:
+			reason = fmt.Sprintf("the %d filled squares of the clues are all found", l.totalClues)
+		case h.Algorithm == lineDeduction:
+			reason = fmt.Sprintf("the %d blanks of the line are all found", l.length-l.totalClues)
+		case color == backgroundColor && l.totalClues == 0 && !l.unknown:
+			reason = fmt.Sprintf("the %s has no clue", l.kind())
+		case color == backgroundColor && len(l.cluesReaching(first, last, -1)) == 0:
+			reason = fmt.Sprintf("no clue can reach %s", subject)
+			subject = "it"
+			if last > first {
+				subject = "they"
+			}
+		default:
+			reason = hintTechniques[h.Algorithm].reason
+			if cs := l.cluesReaching(first, last, color); color != backgroundColor && len(cs) == 1 && cs[0].overlaps(first, last) {
+				reason = fmt.Sprintf("%s can only fit in %s", cs[0].text(), l.squaresText(cs[0].begin, cs[0].end))
+			}
+		}
+		reasons = append(reasons, fmt.Sprintf("%s, so %s %s %s", reason, subject, verb, g.colorText(color)))
+	}
+	return strings.Join(reasons, "; ")
+}
+
+// cluesReaching returns the clues of the given color, any one if negative,
+// whose limits include the squares from first to last, the clue 0 of an empty
+// line reaching nothing
+func (l *Line) cluesReaching(first, last, color int) [](*Clue) {
+	result := make([](*Clue), 0)
+	for _, c := range l.clues {
+		if c.length > 0 && c.begin <= first && last <= c.end && (color < 0 || c.color == color) {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+// overlaps indicates if the clue covers the squares from first to last
+// wherever it is placed within its limits
+func (c *Clue) overlaps(first, last int) bool {
+	return c.end-c.length+1 <= first && last <= c.begin+c.length-1
+}
+
+// text designates the clue in a hint, e.g. "clue 3 of length 6"
+func (c *Clue) text() string {
+	text := fmt.Sprintf("clue %d of length %d", c.index+1, c.length)
+	if c.isUnknown() {
+		text = fmt.Sprintf("clue %d of unknown length", c.index+1)
+	}
+	if c.color != defaultColor {
+		text += " in color " + c.l.g.palette[c.color].Name
+	}
+	return text
+}
+
+// squaresText designates the squares of the line from first to last (0-based)
+// in a hint: by their column on a line, by their line on a column
+func (l *Line) squaresText(first, last int) string {
+	name := "column"
+	switch {
+	case l.g.isTriddler:
+		name = "square"
+	case l.isColumn():
+		name = "line"
+	}
+	if first == last {
+		return fmt.Sprintf("%s %d", name, first+1)
+	}
+	return fmt.Sprintf("%ss %d–%d", name, first+1, last+1)
+}
+
+// colorText returns the value of a square of the given palette index in a hint
+func (g *Griddler) colorText(color int) string {
+	switch color {
+	case backgroundColor:
+		return "blank"
+	case defaultColor:
+		return "filled"
+	}
+	return "filled in color " + g.palette[color].Name
+}
+
+// limitsKey returns the limits of all the clues, to detect the progress of
+// the algorithms
+func (g *Griddler) limitsKey() string {
+	var b strings.Builder
+	for _, ls := range g.axes {
+		for _, l := range ls {
+			b.WriteString(l.limitsString())
+			b.WriteByte('\n')
+		}
+	}
+	return b.String()
+}
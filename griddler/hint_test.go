@@ -0,0 +1,60 @@
+package griddler
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestHintThenSolve(t *testing.T) {
+	files, err := filepath.Glob("../data/*.grid.done")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		t.Run(filepath.Base(f), func(t *testing.T) {
+			data, err := os.ReadFile(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			load := func() *Griddler {
+				g := New(SolverOptions{})
+				if err := g.Parse(data); err != nil {
+					t.Fatal(err)
+				}
+				return g
+			}
+
+			g := load()
+			found, stacked := g.found, make([]int, len(g.stacks))
+			for k, st := range g.stacks {
+				stacked[k] = len(st)
+			}
+			if _, err := g.Hint(); err != nil {
+				t.Fatal(err)
+			}
+			if g.found != found || g.isInit {
+				t.Errorf("the hint changed the counters")
+			}
+			for k, st := range g.stacks {
+				if len(st) != stacked[k] {
+					t.Errorf("the hint left %d lines in the stack %d, expected %d", len(st), k, stacked[k])
+				}
+			}
+
+			// the hint leaves the solving and its rating unchanged
+			rating, err := g.Rate()
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected, err := load().Rate()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(rating, expected) {
+				t.Errorf("got the rating %+v after a hint, expected %+v", rating, expected)
+			}
+		})
+	}
+}
@@ -2,19 +2,26 @@ package griddler
 
 import (
 	"fmt"
+	"strings"
 )
 
 type Line struct {
 	g          *Griddler
+	axis       int // index of the line family in the griddler axes
 	index      int
 	length     int
 	clues      [](*Clue)
 	squares    [](*Square)
 	sumBlanks  int
 	sumClues   int // current sum of all clue values
-	totalClues int // total sum of all clues evaluated
+	totalClues int // total sum of all clues evaluated, the minimal one if some are unknown
 	cb, ce     int // indexes of the first and last non solved clue
 	isDone     bool
+	isStacked  bool // the line is waiting in a solving stack
+	isChanged  bool // squares were found since the fallback algorithms last ran on the line
+	round      int  // logic round in which the line was queued, see Rating.Rounds
+	unknown    bool // the length of some clues, or the clues themselves ("*"), are hidden
+	src        int  // line of the definition in the source file, 0 if unknown
 }
 
 func NewLine(g *Griddler, index, length int) *Line {
@@ -26,10 +33,59 @@ func NewLine(g *Griddler, index, length int) *Line {
 		sumBlanks:  0,
 		sumClues:   0,
 		totalClues: 0,
+		ce:         -1, // no clue until they are added
+		isChanged:  true,
 		isDone:     false,
 	}
 }
 
+// isColumn indicates if the line is a column of its griddler
+func (l *Line) isColumn() bool {
+	return !l.g.isTriddler && l.axis == 1
+}
+
+// kind returns the name of the line family, for display purposes
+func (l *Line) kind() string {
+	return l.g.axisNames()[l.axis]
+}
+
+// isHidden indicates if the clues of the line are hidden, any content being
+// then valid
+func (l *Line) isHidden() bool {
+	return l.unknown && len(l.clues) == 0
+}
+
+// cluesString returns the clue lengths, followed by the name of their color,
+// separated by commas as in the native format
+func (l *Line) cluesString() string {
+	if l.isHidden() {
+		return hiddenClues
+	}
+	s := make([]string, len(l.clues))
+	for i, c := range l.clues {
+		s[i] = c.label() + l.g.palette[c.color].Name
+	}
+	return strings.Join(s, ",")
+}
+
+// gap returns the minimal number of blank squares after the clue i: clues of
+// different colors can touch each other
+func (l *Line) gap(i int) int {
+	if i+1 < len(l.clues) && l.clues[i+1].color == l.clues[i].color {
+		return 1
+	}
+	return 0
+}
+
+// minSize returns the minimal number of squares needed by the clues
+func (l *Line) minSize() int {
+	size := 0
+	for i, c := range l.clues {
+		size += c.length + l.gap(i)
+	}
+	return size
+}
+
 func (l *Line) print(prefix string) {
 	fmt.Printf("%s-->Line: cb:%d, ce:%d\n", prefix, l.cb+1, l.ce+1)
 }
@@ -37,6 +93,11 @@ func (l *Line) print(prefix string) {
 func (l *Line) addClues(cs [](*Clue)) {
 	l.clues = cs
 	for i, val := range cs {
+		// an unknown clue can take the whole line
+		if val.maxLen < 0 {
+			val.maxLen = l.length
+		}
+		l.unknown = l.unknown || val.isUnknown()
 		l.totalClues += val.length
 		val.l = l
 		val.index = i
@@ -45,6 +106,53 @@ func (l *Line) addClues(cs [](*Clue)) {
 	l.ce = len(cs) - 1
 }
 
+// save copies the solving state of the line for the saved griddler g
+func (l *Line) save(g *Griddler) *Line {
+	result := &Line{
+		g:          g,
+		axis:       l.axis,
+		index:      l.index,
+		length:     l.length,
+		clues:      make([](*Clue), len(l.clues)),
+		sumBlanks:  l.sumBlanks,
+		sumClues:   l.sumClues,
+		totalClues: l.totalClues,
+		cb:         l.cb,
+		ce:         l.ce,
+		isDone:     l.isDone,
+		isChanged:  l.isChanged,
+		unknown:    l.unknown,
+		src:        l.src,
+	}
+	for i, c := range l.clues {
+		result.clues[i] = &Clue{
+			l:      result,
+			index:  i,
+			length: c.length,
+			maxLen: c.maxLen,
+			color:  c.color,
+			begin:  c.begin,
+			end:    c.end,
+		}
+	}
+	return result
+}
+
+// restore sets back the solving state saved in clone
+func (l *Line) restore(clone *Line) {
+	l.sumBlanks = clone.sumBlanks
+	l.sumClues = clone.sumClues
+	l.totalClues = clone.totalClues
+	l.cb = clone.cb
+	l.ce = clone.ce
+	l.isDone = clone.isDone
+	l.isChanged = clone.isChanged
+	for i, c := range clone.clues {
+		l.clues[i].begin = c.begin
+		l.clues[i].end = c.end
+	}
+}
+
 func (l *Line) incrementBlanks() {
 	l.sumBlanks++
 	if (l.sumBlanks+l.sumClues) == l.length && !l.isDone {
@@ -98,7 +206,7 @@ func (l *Line) incrementCluesBegin(begC *Clue, n int) {
 			//l.print("incrementCluesBegin")
 			//l.clues[i].print("incrementCluesBegin")
 			//Pause()
-			panic(&SolveError{&Square{x: l.index, y: l.index}, ErrInvalidClueSize})
+			panic(&SolveError{l: l, c: l.clues[i], err: ErrInvalidClueSize})
 		}
 	}
 }
@@ -124,7 +232,7 @@ func (l *Line) decrementCluesEnd(endC *Clue, n int) {
 			//l.print("incrementCluesBegin")
 			//l.clues[i].print("incrementCluesBegin")
 			//Pause()
-			panic(&SolveError{&Square{x: l.index, y: l.index}, ErrInvalidClueSize})
+			panic(&SolveError{l: l, c: l.clues[i], err: ErrInvalidClueSize})
 		}
 	}
 }
@@ -142,6 +250,41 @@ func (l *Line) updateClueIndexes(c *Clue) {
 	}
 }
 
+// matchesClues indicates if the filled squares of a completed line form exactly its
+// clues, with their colors, a clue of length 0 being the notation of an empty line
+// and an unknown clue matching any length in its range
+func (l *Line) matchesClues() bool {
+	if l.isHidden() {
+		return true
+	}
+	cs := make([](*Clue), 0, len(l.clues))
+	for _, c := range l.clues {
+		if c.length > 0 {
+			cs = append(cs, c)
+		}
+	}
+	iClue, run, color := 0, 0, backgroundColor
+	// the last iteration closes a run reaching the end of the line
+	for i := 0; i <= l.length; i++ {
+		current := backgroundColor
+		if i < l.length && l.squares[i].value == FILLED {
+			current = l.squares[i].color
+		}
+		if run > 0 && current != color {
+			if iClue >= len(cs) || run < cs[iClue].length || run > cs[iClue].maxLen || cs[iClue].color != color {
+				return false
+			}
+			iClue++
+			run = 0
+		}
+		if current != backgroundColor {
+			run++
+		}
+		color = current
+	}
+	return iClue == len(cs)
+}
+
 func (l *Line) checkRangeForValue(value int, min, max int) bool {
 	if min < 0 || max >= l.length {
 		return false
@@ -207,7 +350,7 @@ func (l *Line) getUnsolvedRanges() [](*Range) {
 		case s.value == EMPTY, s.value == BLANK:
 			if lastVal == FILLED {
 				max = i - 1
-				if l.squares[min-1].value != BLANK || l.squares[max+1].value != BLANK {
+				if (min > 0 && l.squares[min-1].value != BLANK) || l.squares[max+1].value != BLANK {
 					result = append(result, &Range{min: min, max: max})
 				}
 			}
@@ -218,7 +361,7 @@ func (l *Line) getUnsolvedRanges() [](*Range) {
 			}
 			if i == l.clues[l.ce].end {
 				max = i
-				if l.squares[min-1].value != BLANK {
+				if min > 0 && l.squares[min-1].value != BLANK {
 					result = append(result, &Range{min: min, max: max})
 				}
 			}
@@ -308,7 +451,7 @@ LoopBegin:
 	for iRange < len(rs) {
 		// if we didn't mapped all clue by this time, this is a puzzle issue
 		if iClue > l.ce {
-			panic(&SolveError{&Square{x: l.index, y: l.index}, ErrInvalidClueRange})
+			panic(&SolveError{l: l, err: ErrInvalidClueRange})
 		}
 
 		c := l.clues[iClue]
@@ -408,7 +551,7 @@ LoopEnd:
 	for iRange >= 0 {
 		// if we didn't mapped all clue by this time, this is a puzzle issue
 		if iClue < l.cb {
-			panic(&SolveError{&Square{x: l.index, y: l.index}, ErrInvalidClueRange})
+			panic(&SolveError{l: l, err: ErrInvalidClueRange})
 		}
 
 		c := l.clues[iClue]
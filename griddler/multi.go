@@ -0,0 +1,189 @@
+package griddler
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Multi is a multi-griddler: several griddlers overlapping on some of their
+// squares, typically in their corners, which are solved together. Just as the
+// lines and the columns of a griddler reference the same squares, the griddlers
+// share the squares of their overlapping regions, so a square found on one of
+// them schedules the lines of every griddler containing it.
+type Multi struct {
+	parts  [](*Griddler)
+	cells  map[position]multiCell
+	shared map[*Square][]lineRef // lines of every griddler containing a shared square
+}
+
+// position locates a square in the multi-griddler
+type position struct {
+	line, column int
+}
+
+type multiCell struct {
+	s *Square
+	g *Griddler // griddler owning the square
+}
+
+// NewMulti creates an empty multi-griddler, see Add
+func NewMulti() *Multi {
+	return &Multi{
+		cells:  map[position]multiCell{},
+		shared: map[*Square][]lineRef{},
+	}
+}
+
+// Add places a loaded griddler in the multi-griddler, its top left square at the
+// given 0-based line and column: where it overlaps the griddlers already added,
+// its lines and columns reference their squares instead of its own ones. The
+// griddler must then be solved through the multi-griddler only.
+func (m *Multi) Add(g *Griddler, line, column int) error {
+	if g.isTriddler {
+		return ErrUnsupportedPuzzle
+	}
+	if g.isInit || g.shared != nil {
+		return ErrInvalidMultiPart
+	}
+	if len(m.parts) > 0 && !samePalette(m.parts[0].palette, g.palette) {
+		return ErrPaletteMismatch
+	}
+	for i, l := range g.lines {
+		for j, s := range l.squares {
+			pos := position{line + i, column + j}
+			cell, ok := m.cells[pos]
+			if !ok {
+				m.cells[pos] = multiCell{s, g}
+				continue
+			}
+			refs := cell.g.linesOf(cell.s)
+			l.squares[j] = cell.s
+			g.columns[j].squares[i] = cell.s
+			m.shared[cell.s] = append(refs, lineRef{l, j}, lineRef{g.columns[j], i})
+		}
+	}
+	g.shared = m.shared
+	m.parts = append(m.parts, g)
+	return nil
+}
+
+// Parts returns the griddlers of the multi-griddler, in the order they were added
+func (m *Multi) Parts() [](*Griddler) {
+	return m.parts
+}
+
+// Solve completes the griddlers together by logic, the lines of each one being
+// solved in turn until no more deduction can be made on any of them. The
+// trial&error phase is not available on a multi-griddler.
+func (m *Multi) Solve() (Result, error) {
+	res := Result{}
+	for _, g := range m.parts {
+		if err := g.opts.check(); err != nil {
+			return res, err
+		}
+		if err := g.Validate(); err != nil {
+			return res, err
+		}
+	}
+	// all the clue limits are initialized before any line is solved
+	for _, g := range m.parts {
+		if err := g.solveInit(); err != nil {
+			g.emit(Event{Kind: Contradiction, Err: err})
+			return res, err
+		}
+	}
+
+	for _, g := range m.parts {
+		g.phase = PhaseLogic
+		g.emit(Event{Kind: PhaseStart})
+	}
+	if err := m.solveByLogic(); err != nil {
+		for _, g := range m.parts {
+			g.emit(Event{Kind: Contradiction, Err: err})
+		}
+		return res, err
+	}
+
+	res.Solved = m.isDone()
+	for _, g := range m.parts {
+		g.emit(Event{Kind: SolveEnd, Result: &res})
+	}
+	return res, nil
+}
+
+func (m *Multi) solveByLogic() (err error) {
+	defer recoverSolveError(&err)
+	for m.isStacked() {
+		for _, g := range m.parts {
+			g.solveGeneric()
+		}
+	}
+	for _, g := range m.parts {
+		g.verify()
+	}
+	return
+}
+
+// isStacked indicates if a line of any griddler is waiting to be solved
+func (m *Multi) isStacked() bool {
+	for _, g := range m.parts {
+		if g.isStacked() {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Multi) isDone() bool {
+	for _, g := range m.parts {
+		if !g.isDone() {
+			return false
+		}
+	}
+	return true
+}
+
+// Show prints the current state of the multi-griddler on the standard output
+func (m *Multi) Show() {
+	m.Print(os.Stdout)
+}
+
+// Print writes the squares of all the griddlers at their position, the squares
+// outside of any griddler being left as spaces
+func (m *Multi) Print(w io.Writer) {
+	var last position
+	for pos := range m.cells {
+		last.line = max(last.line, pos.line)
+		last.column = max(last.column, pos.column)
+	}
+	lw := labelWidth(last.line + 1)
+	border := fmt.Sprintf("%s+%s+\n", strings.Repeat(" ", lw+1), strings.Repeat("-", last.column+1))
+	fmt.Fprint(w, border)
+	for i := 0; i <= last.line; i++ {
+		fmt.Fprintf(w, "%*d |", lw, i+1)
+		for j := 0; j <= last.column; j++ {
+			if cell, ok := m.cells[position{i, j}]; ok {
+				cell.g.showSquare(w, cell.s)
+			} else {
+				fmt.Fprint(w, " ")
+			}
+		}
+		fmt.Fprintf(w, "| %-*d\n", lw, i+1)
+	}
+	fmt.Fprint(w, border)
+}
+
+// samePalette indicates if both palettes define the same colors in the same order
+func samePalette(p1, p2 []Color) bool {
+	if len(p1) != len(p2) {
+		return false
+	}
+	for i := range p1 {
+		if p1[i] != p2[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,86 @@
+package griddler
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMulti(t *testing.T) {
+	// alone, the first griddler has two solutions, its diagonals, the second one
+	// overlapping its bottom right square tells them apart
+	defs := []string{
+		"2x2\nH:1;1\nH:2;1\nV:1;1\nV:2;1\n",
+		"2x2\nH:1;2\nH:2;0\nV:1;1\nV:2;1\n",
+	}
+	m := NewMulti()
+	for i, def := range defs {
+		g := New(SolverOptions{})
+		if err := g.Parse([]byte(def)); err != nil {
+			t.Fatal(err)
+		}
+		if err := m.Add(g, i, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	first, second := m.Parts()[0], m.Parts()[1]
+	shared := first.lines[1].squares[1]
+	if first.columns[1].squares[1] != shared || second.lines[0].squares[0] != shared || second.columns[0].squares[0] != shared {
+		t.Fatal("the overlapping square is not shared")
+	}
+	if refs := second.linesOf(shared); len(refs) != 4 {
+		t.Errorf("the shared square is in %d lines, expected 4", len(refs))
+	}
+
+	res, err := m.Solve()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Solved {
+		t.Fatal("not solved")
+	}
+	expected := [][]int{
+		{FILLED, BLANK},
+		{BLANK, FILLED},
+		{FILLED, FILLED},
+		{BLANK, BLANK},
+	}
+	for k, g := range m.Parts() {
+		for i, l := range g.lines {
+			for j, s := range l.squares {
+				if s.value != expected[2*k+i][j] {
+					t.Errorf("square %d,%d of the griddler %d is %d", i+1, j+1, k+1, s.value)
+				}
+			}
+		}
+	}
+}
+
+func TestMultiAdd(t *testing.T) {
+	m := NewMulti()
+	g := New(SolverOptions{})
+	if err := g.Parse([]byte("triddler 2\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Add(g, 0, 0); !errors.Is(err, ErrUnsupportedPuzzle) {
+		t.Errorf("got %v for a triddler", err)
+	}
+
+	g = New(SolverOptions{})
+	if err := g.Parse([]byte("1x1\nC:r;ff0000\nH:1;1r\nV:1;1r\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Add(g, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Add(g, 1, 1); !errors.Is(err, ErrInvalidMultiPart) {
+		t.Errorf("got %v for a griddler added twice", err)
+	}
+	g = New(SolverOptions{})
+	if err := g.Parse([]byte("1x1\nH:1;1\nV:1;1\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Add(g, 1, 1); !errors.Is(err, ErrPaletteMismatch) {
+		t.Errorf("got %v for another palette", err)
+	}
+}
@@ -0,0 +1,136 @@
+package griddler
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Simon Tatham's .non format, made of keyword lines:
+//
+//	width 5
+//	height 3
+//	rows
+//	2
+//	1,1
+//	...
+//	columns
+//	...
+//
+// the optional goal keyword gives the solution as a string of 0 and 1
+func sniffNon(data []byte) bool {
+	return bytes.Contains(data, []byte("width")) && bytes.Contains(data, []byte("rows"))
+}
+
+func (g *Griddler) loadNon(r io.Reader) error {
+	width, height := 0, 0
+	var rows, columns [][](*Clue)
+	goal := ""
+
+	scanner := newScanner(r)
+	line := 0
+	// readClues reads the n lines following a rows or columns keyword
+	readClues := func(n int) ([][](*Clue), error) {
+		result := make([][](*Clue), 0, n)
+		for len(result) < n && scanner.Scan() {
+			line++
+			cs, err := parseClues(scanner.Text())
+			if err != nil {
+				return nil, g.error(err, line)
+			}
+			result = append(result, cs)
+		}
+		if len(result) != n {
+			return nil, g.error(ErrMissingClues, line)
+		}
+		return result, nil
+	}
+
+	for scanner.Scan() {
+		line++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		var err error
+		switch fields[0] {
+		case "width", "height":
+			if len(fields) != 2 {
+				return g.error(ErrInvalidGridSizeFormat, line)
+			}
+			n, err := strconv.Atoi(fields[1])
+			if err != nil || n <= 0 {
+				return g.error(ErrInvalidGridSizeValue, line)
+			}
+			if fields[0] == "width" {
+				width = n
+			} else {
+				height = n
+			}
+		case "rows":
+			if height == 0 {
+				return g.error(ErrInvalidGridSizeFormat, line)
+			}
+			rows, err = readClues(height)
+		case "columns":
+			if width == 0 {
+				return g.error(ErrInvalidGridSizeFormat, line)
+			}
+			columns, err = readClues(width)
+		case "goal":
+			if len(fields) == 2 {
+				goal = strings.Trim(fields[1], "\"")
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if rows == nil || columns == nil {
+		return g.error(ErrMissingClues, line)
+	}
+
+	g.setSize(width, height)
+	for i, cs := range rows {
+		g.lines[i].addClues(cs)
+	}
+	for i, cs := range columns {
+		g.columns[i].addClues(cs)
+	}
+	if len(goal) == width*height {
+		g.goal = newSolution(width, height)
+		for i, ch := range goal {
+			color := backgroundColor
+			if ch == '1' {
+				color = defaultColor
+			}
+			g.goal.set(i/width, i%width, color)
+		}
+	}
+	return nil
+}
+
+func (g *Griddler) writeNon(w io.Writer) error {
+	if g.isTriddler {
+		return ErrUnsupportedPuzzle
+	}
+	if g.isColored() {
+		return ErrUnsupportedColors
+	}
+	if g.hasUnknownClues() {
+		return ErrUnsupportedUnknown
+	}
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "width %d\nheight %d\n\nrows\n", g.width, g.height)
+	for _, l := range g.lines {
+		fmt.Fprintln(&b, joinClues(clueLengths(l), ","))
+	}
+	fmt.Fprintf(&b, "\ncolumns\n")
+	for _, c := range g.columns {
+		fmt.Fprintln(&b, joinClues(clueLengths(c), ","))
+	}
+	_, err := w.Write(b.Bytes())
+	return err
+}
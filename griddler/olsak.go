@@ -0,0 +1,232 @@
+package griddler
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Olsak's .g format, where '#' starts a comment and the clues of the rows then
+// of the columns follow a line starting with ':', one line per row or column
+// with the lengths separated by spaces:
+//
+//	: rows
+//	2
+//	1 1
+//	: columns
+//	...
+//
+// A colored puzzle starts with a #d section giving the color table, one line
+// per color with its index, its char, its value and its name, the index 0
+// being the background and 1 the default color. A clue is then followed by
+// the char of its color when it is not the default one:
+//
+//	#d
+//	   0:   .   #FFFFFF   white
+//	   1:   X   #000000   black
+//	   2:   r   #FF0000   red
+//	: rows
+//	2r 1
+//	...
+func sniffOlsak(data []byte) bool {
+	for _, l := range strings.Split(string(data), "\n") {
+		l = strings.TrimSpace(l)
+		if l == "" || strings.HasPrefix(l, "#") || olsakColorLine.MatchString(l) {
+			continue
+		}
+		return strings.HasPrefix(l, ":")
+	}
+	return false
+}
+
+// olsakColorLine matches a line of the color table: index, char, value and
+// optional name
+var olsakColorLine = regexp.MustCompile(`^(\d+):\s*(\S)\s+(#?[0-9A-Fa-f]{6})(\s.*)?$`)
+
+// olsakColor is a color of the table of an Olsak file
+type olsakColor struct {
+	char byte
+	rgb  string
+}
+
+func (g *Griddler) loadOlsak(r io.Reader) error {
+	sections := make([][][](*Clue), 0, 2)
+	colors := make([]olsakColor, 0)
+	// palette index of each char of the color table, known once the table
+	// is complete
+	var indexes map[byte]int
+
+	scanner := newScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(text, "#"):
+		case strings.HasPrefix(text, ":"):
+			if indexes == nil {
+				var err error
+				if indexes, err = olsakIndexes(colors); err != nil {
+					return g.error(err, line)
+				}
+			}
+			sections = append(sections, make([][](*Clue), 0))
+		case len(sections) == 0:
+			if m := olsakColorLine.FindStringSubmatch(text); m != nil {
+				// the colors are listed in the order of their index
+				if m[1] != strconv.Itoa(len(colors)) {
+					return g.error(ErrInvalidTokenLine, line)
+				}
+				colors = append(colors, olsakColor{m[2][0], m[3]})
+				continue
+			}
+			if text != "" {
+				return g.error(ErrInvalidTokenLine, line)
+			}
+		case text == "":
+		default:
+			cs, err := parseOlsakClues(text, indexes)
+			if err != nil {
+				return g.error(err, line)
+			}
+			sections[len(sections)-1] = append(sections[len(sections)-1], cs)
+		}
+	}
+	if len(sections) < 2 || len(sections[0]) == 0 || len(sections[1]) == 0 {
+		return g.error(ErrMissingClues, line)
+	}
+
+	rows, columns := sections[0], sections[1]
+	g.setSize(len(columns), len(rows))
+	if err := g.addOlsakColors(colors); err != nil {
+		return g.error(err, line)
+	}
+	for i, cs := range rows {
+		g.lines[i].addClues(cs)
+	}
+	for i, cs := range columns {
+		g.columns[i].addClues(cs)
+	}
+	return nil
+}
+
+// olsakIndexes returns the palette index of each char of the color table, the
+// colors beyond the background and the default one being added in order
+func olsakIndexes(colors []olsakColor) (map[byte]int, error) {
+	indexes := map[byte]int{}
+	if len(colors) > maxColors {
+		return nil, ErrTooManyColors
+	}
+	for i, c := range colors {
+		if _, ok := indexes[c.char]; ok {
+			return nil, ErrDuplicateColor
+		}
+		indexes[c.char] = i
+	}
+	return indexes, nil
+}
+
+// addOlsakColors fills the palette with the color table, a color being named
+// by its char when it is a valid color name, by the first letter available
+// otherwise
+func (g *Griddler) addOlsakColors(colors []olsakColor) error {
+	for i, c := range colors {
+		rgb, err := parseRGB(c.rgb)
+		if err != nil {
+			return err
+		}
+		if i <= defaultColor {
+			g.palette[i].RGB = rgb
+			continue
+		}
+		name := string(c.char)
+		if !isColorName(name) || g.colorIndex(name) >= 0 {
+			name = g.freeColorName()
+		}
+		if _, err := g.AddColor(name, rgb); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseOlsakClues reads the clue lengths separated by spaces, each one being
+// possibly followed by the char of its color
+func parseOlsakClues(s string, indexes map[byte]int) ([](*Clue), error) {
+	fields := strings.Fields(s)
+	cs := make([](*Clue), 0, len(fields))
+	for _, f := range fields {
+		suffix := strings.TrimLeft(f, "0123456789")
+		n, err := strconv.Atoi(f[:len(f)-len(suffix)])
+		if err != nil {
+			return nil, ErrInvalidIntValue
+		}
+		color := defaultColor
+		if suffix != "" {
+			index, ok := indexes[suffix[0]]
+			if len(suffix) != 1 || !ok || index == backgroundColor {
+				return nil, ErrUnknownColor
+			}
+			color = index
+		}
+		if n > 0 {
+			c := NewClue(n)
+			c.color = color
+			cs = append(cs, c)
+		}
+	}
+	return cs, nil
+}
+
+func (g *Griddler) writeOlsak(w io.Writer) error {
+	if g.isTriddler {
+		return ErrUnsupportedPuzzle
+	}
+	if g.hasUnknownClues() {
+		return ErrUnsupportedUnknown
+	}
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "# %dx%d\n", g.width, g.height)
+	if g.isColored() {
+		// the background and the default color have no name in the palette
+		fmt.Fprintf(&b, "#d\n")
+		for i, c := range g.palette {
+			char := c.Name
+			switch i {
+			case backgroundColor:
+				char = "."
+			case defaultColor:
+				char = "X"
+			}
+			fmt.Fprintf(&b, "   %d:   %s   #%s\n", i, char, hexRGB(c.RGB))
+		}
+	}
+	fmt.Fprintf(&b, ": rows\n")
+	for _, l := range g.lines {
+		fmt.Fprintln(&b, olsakClues(l))
+	}
+	fmt.Fprintf(&b, ": columns\n")
+	for _, c := range g.columns {
+		fmt.Fprintln(&b, olsakClues(c))
+	}
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// olsakClues formats the clues of the line separated by spaces, followed by
+// the name of their color, "0" for an empty line
+func olsakClues(l *Line) string {
+	s := make([]string, 0, len(l.clues))
+	for _, c := range l.clues {
+		if c.length > 0 {
+			s = append(s, strconv.Itoa(c.length)+l.g.palette[c.color].Name)
+		}
+	}
+	if len(s) == 0 {
+		return "0"
+	}
+	return strings.Join(s, " ")
+}
@@ -0,0 +1,53 @@
+package griddler
+
+import "time"
+
+// SolverOptions configures the strategies used to solve a griddler
+type SolverOptions struct {
+	UseTrial      bool          // enable the trial&error phase when logic is not enough
+	MaxTrialDepth int           // maximum depth of the trial&error hypotheses, 0 means 1
+	TrialTimeout  time.Duration // time allowed to the trial&error phase of Solve and to the search of Solutions, no limit if 0
+	// TwoSidedProbing makes the trial&error phase try both values of each candidate,
+	// the squares having the same value in both outcomes being then certain
+	TwoSidedProbing bool
+	Events          EventSink // receiver of the solving progress, nothing is reported if nil
+	Algorithms      []string  // names of the line algorithms to enable, all if empty, the costly solveExact included
+}
+
+// check verifies that the options are consistent
+func (o *SolverOptions) check() error {
+	if o.MaxTrialDepth < 0 {
+		return ErrInvalidTrialDepth
+	}
+	for _, name := range o.Algorithms {
+		if findAlgorithm(name) == nil {
+			return ErrUnknownAlgorithm
+		}
+	}
+	return nil
+}
+
+func (o *SolverOptions) trialDepth() int {
+	if o.MaxTrialDepth == 0 {
+		return 1
+	}
+	return o.MaxTrialDepth
+}
+
+// algorithms returns the line algorithms selected by the options, in the
+// order of the default solving sequence
+func (o *SolverOptions) algorithms() []namedAlgorithm {
+	if len(o.Algorithms) == 0 {
+		return solveAlgorithms
+	}
+	result := make([]namedAlgorithm, 0, len(o.Algorithms))
+	for _, na := range solveAlgorithms {
+		for _, name := range o.Algorithms {
+			if na.name == name {
+				result = append(result, na)
+				break
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,172 @@
+package griddler
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"strconv"
+)
+
+// ImageOptions configures the raster rendering of a griddler
+type ImageOptions struct {
+	CellSize  int         // size of a square in pixels, 8 if 0
+	ShowClues bool        // draw the clue margins with a bitmap font
+	Filled    color.Color // color of the filled squares and of the clues, black if nil, the other colors coming from the palette
+	Blank     color.Color // color of the blank squares and of the margins, white if nil
+	Empty     color.Color // color of the squares not found yet, light gray if nil
+}
+
+const defaultImageCellSize = 8
+
+func (o *ImageOptions) cellSize() int {
+	if o.CellSize <= 0 {
+		return defaultImageCellSize
+	}
+	return o.CellSize
+}
+
+func (o *ImageOptions) colors() (filled, blank, empty color.Color) {
+	filled, blank, empty = o.Filled, o.Blank, o.Empty
+	if filled == nil {
+		filled = color.Black
+	}
+	if blank == nil {
+		blank = color.White
+	}
+	if empty == nil {
+		empty = color.Gray{Y: 0xd0}
+	}
+	return
+}
+
+// glyphs is a 3x5 bitmap font for the clues, each row of a character being
+// encoded on 3 bits
+var glyphs = map[rune][5]uint8{
+	'0': {7, 5, 5, 5, 7},
+	'1': {2, 6, 2, 2, 7},
+	'2': {7, 1, 7, 4, 7},
+	'3': {7, 1, 7, 1, 7},
+	'4': {5, 5, 7, 1, 1},
+	'5': {7, 4, 7, 1, 7},
+	'6': {7, 4, 7, 5, 7},
+	'7': {7, 1, 1, 1, 1},
+	'8': {7, 5, 7, 5, 7},
+	'9': {7, 5, 7, 1, 7},
+	'?': {7, 1, 3, 0, 2},
+	'*': {0, 5, 2, 5, 0},
+}
+
+// Image draws the current state of the board, one block of pixels per square,
+// the triddlers being not supported
+func (g *Griddler) Image(opts ImageOptions) (*image.RGBA, error) {
+	if g.isTriddler {
+		return nil, ErrUnsupportedPuzzle
+	}
+	cs := opts.cellSize()
+	filled, blank, empty := opts.colors()
+
+	// the glyphs are scaled with the squares, a clue being centered in a slot,
+	// the slots being large enough for a clue as long as a line: the digits of
+	// the clues of a column are stacked to stay within its width, the clues being
+	// further apart than their digits
+	scale := max(cs/defaultImageCellSize, 1)
+	left, top := 0, 0
+	if opts.ShowClues {
+		left = maxClues(g.lines) * max(cs, textWidth(strconv.Itoa(g.width), scale)+2*scale)
+		top = maxClues(g.columns) * max(cs, textHeight(strconv.Itoa(g.height), scale)+3*scale)
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, left+g.width*cs, top+g.height*cs))
+	draw.Draw(img, img.Bounds(), &image.Uniform{blank}, image.Point{}, draw.Src)
+
+	for i, l := range g.lines {
+		for j, s := range l.squares {
+			c := empty
+			switch s.value {
+			case FILLED:
+				c = g.imageColor(s.color, filled)
+			case BLANK:
+				c = blank
+			}
+			r := image.Rect(left+j*cs, top+i*cs, left+(j+1)*cs, top+(i+1)*cs)
+			draw.Draw(img, r, &image.Uniform{c}, image.Point{}, draw.Src)
+		}
+	}
+
+	if opts.ShowClues {
+		slot := left / maxClues(g.lines)
+		for i, l := range g.lines {
+			clues := displayedClues(l)
+			for k, c := range clues {
+				x := left - (len(clues)-k)*slot + (slot-textWidth(c.text, scale))/2
+				y := top + i*cs + (cs-5*scale)/2
+				drawText(img, c.text, x, y, scale, g.imageColor(c.color, filled))
+			}
+		}
+		slot = top / maxClues(g.columns)
+		for j, col := range g.columns {
+			clues := displayedClues(col)
+			for k, c := range clues {
+				x := left + j*cs + (cs-textWidth("0", scale))/2
+				y := top - (len(clues)-k)*slot + (slot-textHeight(c.text, scale))/2
+				drawStackedText(img, c.text, x, y, scale, g.imageColor(c.color, filled))
+			}
+		}
+	}
+
+	return img, nil
+}
+
+// imageColor returns the color of the given palette index, the default color
+// being the one of the options
+func (g *Griddler) imageColor(index int, filled color.Color) color.Color {
+	if index == defaultColor {
+		return filled
+	}
+	return g.palette[index].RGB
+}
+
+// WritePNG encodes the image of the board as PNG, the triddlers being not supported
+func (g *Griddler) WritePNG(w io.Writer, opts ImageOptions) error {
+	img, err := g.Image(opts)
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, img)
+}
+
+// textWidth returns the width in pixels of a text drawn with the bitmap font
+func textWidth(s string, scale int) int {
+	return (4*len(s) - 1) * scale
+}
+
+// textHeight returns the height in pixels of a text drawn with the bitmap font,
+// its characters being stacked
+func textHeight(s string, scale int) int {
+	return (6*len(s) - 1) * scale
+}
+
+func drawText(img *image.RGBA, s string, x, y, scale int, c color.Color) {
+	for _, ch := range s {
+		bitmap := glyphs[ch]
+		for row, bits := range bitmap {
+			for col := 0; col < 3; col++ {
+				if bits&(4>>uint(col)) != 0 {
+					r := image.Rect(x+col*scale, y+row*scale, x+(col+1)*scale, y+(row+1)*scale)
+					draw.Draw(img, r, &image.Uniform{c}, image.Point{}, draw.Src)
+				}
+			}
+		}
+		x += 4 * scale
+	}
+}
+
+// drawStackedText draws the characters of the text one below the other
+func drawStackedText(img *image.RGBA, s string, x, y, scale int, c color.Color) {
+	for _, ch := range s {
+		drawText(img, string(ch), x, y, scale, c)
+		y += 6 * scale
+	}
+}
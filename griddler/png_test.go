@@ -0,0 +1,70 @@
+package griddler
+
+import (
+	"errors"
+	"image/color"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestImageClues(t *testing.T) {
+	// 2 full columns of 100 squares, whose 3 digit clues must not overlap
+	var def strings.Builder
+	def.WriteString("2x100\n")
+	for i := 1; i <= 100; i++ {
+		def.WriteString("H:" + strconv.Itoa(i) + ";2\n")
+	}
+	def.WriteString("V:1;100\nV:2;100\n")
+	g := New(SolverOptions{})
+	if err := g.Parse([]byte(def.String())); err != nil {
+		t.Fatal(err)
+	}
+	img, err := g.Image(ImageOptions{ShowClues: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cs := defaultImageCellSize
+	left := img.Bounds().Dx() - 2*cs
+	top := img.Bounds().Dy() - 100*cs
+	if left < textWidth("2", 1) || top < textHeight("100", 1) {
+		t.Fatalf("margins %dx%d too small", left, top)
+	}
+	white := color.RGBAModel.Convert(color.White)
+	inkOf := func(x0, x1 int) int {
+		ink := 0
+		for x := x0; x < x1; x++ {
+			for y := 0; y < top; y++ {
+				if img.At(x, y) != white {
+					ink++
+				}
+			}
+		}
+		return ink
+	}
+	glyphInk := 0
+	for _, ch := range "100" {
+		for _, bits := range glyphs[ch] {
+			glyphInk += int(bits&1 + bits>>1&1 + bits>>2&1)
+		}
+	}
+	for j := 0; j < 2; j++ {
+		if ink := inkOf(left+j*cs, left+(j+1)*cs); ink != glyphInk {
+			t.Errorf("column %d: %d pixels of clues, expected %d", j+1, ink, glyphInk)
+		}
+	}
+	if ink := inkOf(0, img.Bounds().Dx()); ink != 2*glyphInk {
+		t.Errorf("%d pixels of clues above the grid, expected %d", ink, 2*glyphInk)
+	}
+}
+
+func TestImageTriddler(t *testing.T) {
+	g := New(SolverOptions{})
+	if err := g.Parse([]byte("triddler 2\nH:1;1\nH:2;3\nL:1;2\nL:2;1\nR:1;2\nR:2;1\n")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.Image(ImageOptions{}); !errors.Is(err, ErrUnsupportedPuzzle) {
+		t.Errorf("expected %v, got %v", ErrUnsupportedPuzzle, err)
+	}
+}
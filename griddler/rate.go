@@ -0,0 +1,132 @@
+package griddler
+
+import "math"
+
+// Names of the deductions made outside of the line algorithms, see Rating
+const (
+	initDeduction  = "solveInitAlgo" // overlaps of the clues at their initial limits
+	lineDeduction  = "solveLine"     // lines completed once all their clues or blanks are found
+	trialDeduction = "trial"         // squares found thanks to the trial&error phase
+)
+
+// weights of the deductions made outside of the line algorithms
+var deductionWeights = map[string]float64{
+	initDeduction:  1,
+	lineDeduction:  1,
+	trialDeduction: 6,
+}
+
+// Rating describes the difficulty of a puzzle from the deductions needed to solve it
+type Rating struct {
+	Result
+	// Score is the mean difficulty of the deductions, from 1 for the simple
+	// overlaps to 6 for the trial&error, increased by the base 2 logarithm of
+	// the number of contradictions needed (plus one)
+	Score float64
+	// Deductions gives the number of squares found by each line algorithm, as
+	// well as by solveInitAlgo, by the completion of the lines (solveLine) and
+	// by the trial&error phase (trial)
+	Deductions map[string]int
+	// Rounds is the number of logic rounds, a round solving the lines queued by
+	// the squares found in the previous one, or by the overlaps of the clues
+	// for the first one
+	Rounds         int
+	Trial          bool // the trial&error phase was needed
+	Contradictions int  // contradictions found by the trial&error hypotheses
+}
+
+// Algorithms returns the names of the deductions in the rating, in the order
+// they are applied by the solver
+func (r *Rating) Algorithms() []string {
+	names := []string{initDeduction, lineDeduction}
+	names = append(names, AlgorithmNames()...)
+	names = append(names, trialDeduction)
+	result := make([]string, 0, len(r.Deductions))
+	for _, name := range names {
+		if _, ok := r.Deductions[name]; ok {
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
+// Rate solves the griddler and rates its difficulty. The rating only depends on
+// the puzzle and on the solver options, e.g. the algorithms enabled, so it can
+// be used to sort puzzles, except when the trial&error phase is limited by a
+// timeout.
+func (g *Griddler) Rate() (*Rating, error) {
+	r := &Rating{Deductions: map[string]int{}}
+	before := g.knownSquares()
+	g.rating = r
+	defer func() { g.rating = nil }()
+	res, err := g.Solve()
+	if err != nil {
+		return nil, err
+	}
+	r.Result = res
+	r.Trial = res.Trials > 0
+
+	// the squares found by the trial&error phase are the ones no line
+	// algorithm found
+	logic := 0
+	for _, n := range r.Deductions {
+		logic += n
+	}
+	if trial := g.knownSquares() - before - logic; trial > 0 {
+		r.Deductions[trialDeduction] = trial
+	}
+	r.Score = r.score()
+	return r, nil
+}
+
+// score returns the mean weight of the deductions, increased by the logarithm
+// of the contradictions
+func (r *Rating) score() float64 {
+	total, weighted := 0, 0.0
+	for name, n := range r.Deductions {
+		total += n
+		weighted += float64(n) * deductionWeight(name)
+	}
+	score := math.Log2(float64(1 + r.Contradictions))
+	if total > 0 {
+		score += weighted / float64(total)
+	}
+	return score
+}
+
+// deductionWeight returns the difficulty of the deductions of the given name
+func deductionWeight(name string) float64 {
+	for _, na := range solveAlgorithms {
+		if na.name == name {
+			return na.weight
+		}
+	}
+	return deductionWeights[name]
+}
+
+// record adds the squares found since found to the deductions of the given
+// name, as long as they are not the consequences of a trial&error hypothesis
+func (g *Griddler) record(name string, found int) {
+	if g.rating != nil && g.phase != PhaseTrial && g.found > found {
+		g.rating.Deductions[name] += g.found - found
+	}
+}
+
+// countContradiction is meant to be deferred after recoverSolveError, it counts
+// the contradictions of the trial&error hypotheses
+func (g *Griddler) countContradiction(err *error) {
+	if *err != nil && g.rating != nil {
+		g.rating.Contradictions++
+	}
+}
+
+// knownSquares returns the number of squares found
+func (g *Griddler) knownSquares() int {
+	n := 0
+	for _, s := range g.board {
+		if s.value != EMPTY {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,34 @@
+package griddler
+
+import "testing"
+
+func TestRateRounds(t *testing.T) {
+	tests := []struct {
+		name   string
+		def    string
+		rounds int
+	}{
+		// the overlaps solve every line, no round is needed
+		{"overlaps", "3x2\nH:1;3\nH:2;0\nV:1;1\nV:2;1\nV:3;1\n", 0},
+		// the squares found by the overlaps are propagated from a line to the next
+		{"propagation", "4x4\nH:1;3\nH:2;1\nH:3;4\nH:4;1\nV:1;1,1\nV:2;1,1\nV:3;4\nV:4;1\n", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := New(SolverOptions{})
+			if err := g.Parse([]byte(tt.def)); err != nil {
+				t.Fatal(err)
+			}
+			r, err := g.Rate()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.Trial {
+				t.Error("the puzzle needed trial&error")
+			}
+			if r.Rounds != tt.rounds {
+				t.Errorf("expected %d rounds, got %d", tt.rounds, r.Rounds)
+			}
+		})
+	}
+}
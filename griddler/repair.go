@@ -0,0 +1,358 @@
+package griddler
+
+import (
+	"fmt"
+	"image"
+	"io"
+	"sort"
+	"time"
+)
+
+// RepairOptions configures the repair of an ambiguous image, see Repair
+type RepairOptions struct {
+	Solver SolverOptions // algorithms of the logic deciding the puzzle, the trial&error options being ignored
+	// Contrast gives for each pixel the distance of its gray level to the
+	// threshold, see Contrast: the pixels of low contrast are flipped first.
+	// All the pixels have the same contrast if nil.
+	Contrast [][]uint8
+	MaxFlips int           // maximum number of pixels flipped, no limit if 0
+	Timeout  time.Duration // time allowed to the repair, no limit if 0
+}
+
+// Flip is a pixel changed by the repair of an image
+type Flip struct {
+	Line, Column int  // 0-based position of the pixel
+	Filled       bool // new value of the pixel
+}
+
+// Repaired is the outcome of a successful repair
+type Repaired struct {
+	Griddler *Griddler // puzzle of the repaired image, its goal being the image
+	Cells    [][]bool  // repaired image
+	Flips    []Flip    // pixels changed, in the order they were flipped
+}
+
+// repairCandidate is a pixel flip with the number of squares it is expected to
+// decide, weighted by its cost
+type repairCandidate struct {
+	p     position
+	score float64
+}
+
+// Repair makes the puzzle derived from an image solvable by logic alone, and
+// therefore unique: as long as the logic leaves some squares undecided, the
+// pixel flip deciding the most of them is applied, the flips of the pixels on
+// the edges of the shapes or of low contrast costing less. Only the pixels on
+// the boundary of the undecided region are tried, each one being evaluated from
+// the solving state already reached, then the best ones are checked by solving
+// their puzzle from scratch. A pixel is never flipped twice.
+// ErrRepairFailed is returned when no flip helps anymore or when the maximum of
+// flips is reached, and ErrRepairTimedOut once the timeout has expired.
+func Repair(cells [][]bool, opts RepairOptions) (*Repaired, error) {
+	if opts.Contrast != nil && !sameSize(cells, opts.Contrast) {
+		return nil, ErrInvalidBitmap
+	}
+	var deadline time.Time
+	if opts.Timeout > 0 {
+		deadline = time.Now().Add(opts.Timeout)
+	}
+	timedOut := func() bool {
+		return !deadline.IsZero() && time.Now().After(deadline)
+	}
+	// the puzzles solved along the way are not reported to the sink of the options
+	solver := opts.Solver
+	solver.Events = nil
+
+	cells = copyCells(cells)
+	base, err := newRepairState(cells, solver)
+	if err != nil {
+		return nil, err
+	}
+	undecided := emptySquares(base.g)
+	flips := make([]Flip, 0)
+	flipped := map[position]bool{}
+	for len(undecided) > 0 {
+		if opts.MaxFlips > 0 && len(flips) >= opts.MaxFlips {
+			return nil, ErrRepairFailed
+		}
+
+		// the estimates are optimistic, keeping squares decided with the help
+		// of the clues changed by the flip
+		candidates := make([]repairCandidate, 0)
+		for _, p := range flipCandidates(base.g, flipped) {
+			if timedOut() {
+				return nil, ErrRepairTimedOut
+			}
+			cells[p.line][p.column] = !cells[p.line][p.column]
+			remaining, err := base.estimate(cells, p, len(undecided))
+			cells[p.line][p.column] = !cells[p.line][p.column]
+			if err != nil {
+				return nil, err
+			}
+			if gain := len(undecided) - remaining; gain > 0 {
+				candidates = append(candidates, repairCandidate{p, float64(gain) / flipCost(cells, opts.Contrast, p)})
+			}
+		}
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return candidates[i].score > candidates[j].score
+		})
+
+		// so the best candidate is only applied once its puzzle, solved from
+		// scratch, leaves less squares undecided
+		applied := false
+		for _, c := range candidates {
+			if timedOut() {
+				return nil, ErrRepairTimedOut
+			}
+			p := c.p
+			cells[p.line][p.column] = !cells[p.line][p.column]
+			st, err := newRepairState(cells, solver)
+			if err != nil {
+				return nil, err
+			}
+			if remaining := emptySquares(st.g); len(remaining) < len(undecided) {
+				base, undecided, applied = st, remaining, true
+				flips = append(flips, Flip{p.line, p.column, cells[p.line][p.column]})
+				flipped[p] = true
+				break
+			}
+			cells[p.line][p.column] = !cells[p.line][p.column]
+		}
+		if !applied {
+			return nil, ErrRepairFailed
+		}
+	}
+
+	g := New(opts.Solver)
+	if err := g.setSolution(cells); err != nil {
+		return nil, err
+	}
+	return &Repaired{Griddler: g, Cells: cells, Flips: flips}, nil
+}
+
+// WriteDiff writes the flipped pixels, one per line with its 1-based line and
+// column followed by its old and new values, e.g. "3,7: . -> X"
+func (r *Repaired) WriteDiff(w io.Writer) error {
+	chars := map[bool]byte{false: squareChars[BLANK], true: squareChars[FILLED]}
+	for _, f := range r.Flips {
+		if _, err := fmt.Fprintf(w, "%d,%d: %c -> %c\n", f.Line+1, f.Column+1, chars[!f.Filled], chars[f.Filled]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// undecidedSquares solves by logic the puzzle derived from the cells, and
+// returns the position of the squares left empty
+func undecidedSquares(cells [][]bool, opts SolverOptions) ([]position, error) {
+	g, err := solvedByLogic(cells, opts)
+	if err != nil {
+		return nil, err
+	}
+	return emptySquares(g), nil
+}
+
+// solvedByLogic returns the puzzle derived from the cells, solved by logic
+func solvedByLogic(cells [][]bool, opts SolverOptions) (*Griddler, error) {
+	g := New(opts)
+	if err := g.setSolution(cells); err != nil {
+		return nil, err
+	}
+	if err := g.solveInit(); err != nil {
+		return nil, err
+	}
+	if err := g.solveByLogic(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// repairState is the puzzle of the image being repaired solved by logic, from
+// which the flips are estimated: the estimate of a flip records the squares it
+// deduces, so that only their lines are then put back in their saved state
+type repairState struct {
+	g     *Griddler
+	saved *Griddler
+	found []position // squares deduced by the current estimate
+}
+
+// newRepairState solves by logic the puzzle derived from the cells
+func newRepairState(cells [][]bool, opts SolverOptions) (*repairState, error) {
+	g, err := solvedByLogic(cells, opts)
+	if err != nil {
+		return nil, err
+	}
+	st := &repairState{g: g, saved: g.save()}
+	g.opts.Events = st
+	return st, nil
+}
+
+// Event records the squares deduced by the current estimate
+func (st *repairState) Event(g *Griddler, e Event) {
+	if e.Kind == SquareDeduced {
+		st.found = append(st.found, position{e.Line - 1, e.Column - 1})
+	}
+}
+
+// estimate returns the number of squares left undecided by the logic once the
+// pixel p of the image is flipped, out of the given number: the line and the
+// column of p get the clues of the new image and are solved again from their
+// initial clue limits, the other lines keeping their state
+func (st *repairState) estimate(cells [][]bool, p position, undecided int) (int, error) {
+	g := st.g
+	column := make([]bool, len(cells))
+	for i := range cells {
+		column[i] = cells[i][p.column]
+	}
+	ls := [](*Line){g.lines[p.line], g.columns[p.column]}
+	old := [][](*Clue){ls[0].clues, ls[1].clues}
+	for k, cs := range [][](*Clue){cluesOf(cells[p.line]), cluesOf(column)} {
+		ls[k].totalClues = 0
+		ls[k].addClues(cs)
+	}
+
+	s := ls[0].squares[p.column]
+	if s.value != EMPTY {
+		undecided++
+		for _, l := range ls {
+			if s.value == FILLED {
+				l.sumClues--
+			} else {
+				l.sumBlanks--
+			}
+			l.isDone = false
+		}
+		s.value = EMPTY
+	}
+	st.found = st.found[:0]
+	err := g.solveAgain(ls)
+	remaining := undecided - len(st.found)
+
+	// the clue limits are restored with the former clues in place
+	ls[0].clues, ls[1].clues = old[0], old[1]
+	st.restore(p)
+	for _, f := range st.found {
+		st.restore(f)
+	}
+	g.found = st.saved.found
+	// a contradiction leaves lines waiting
+	for k := range g.stacks {
+		for g.stacks[k].pop() != nil {
+		}
+	}
+	return remaining, err
+}
+
+// restore puts back the square at p, its line and its column in their saved state
+func (st *repairState) restore(p position) {
+	g := st.g
+	*g.square(p.line, p.column) = *st.saved.square(p.line, p.column)
+	g.lines[p.line].restore(st.saved.lines[p.line])
+	g.columns[p.column].restore(st.saved.columns[p.column])
+}
+
+// solveAgain solves by logic the given lines from their initial clue limits,
+// and the lines where squares are found along the way
+func (g *Griddler) solveAgain(ls [](*Line)) (err error) {
+	defer recoverSolveError(&err)
+	for _, l := range ls {
+		g.solveInitAlgo(g, l)
+		g.pushLine(l)
+	}
+	g.solveGeneric()
+	return
+}
+
+// emptySquares returns the position of the squares not found yet
+func emptySquares(g *Griddler) []position {
+	result := make([]position, 0)
+	for i, l := range g.lines {
+		for j, s := range l.squares {
+			if s.value == EMPTY {
+				result = append(result, position{i, j})
+			}
+		}
+	}
+	return result
+}
+
+// flipCandidates returns the pixels on the boundary of the undecided region of
+// the griddler, the undecided squares next to a decided one or to the border
+// and the decided squares next to an undecided one, but the pixels already
+// flipped
+func flipCandidates(g *Griddler, flipped map[position]bool) []position {
+	isEmpty := func(i, j int) bool {
+		return i >= 0 && i < g.height && j >= 0 && j < g.width && g.lines[i].squares[j].value == EMPTY
+	}
+	result := make([]position, 0)
+	for i := 0; i < g.height; i++ {
+		for j := 0; j < g.width; j++ {
+			if flipped[position{i, j}] {
+				continue
+			}
+			empty := isEmpty(i, j)
+			for _, d := range []position{{-1, 0}, {1, 0}, {0, -1}, {0, 1}} {
+				if isEmpty(i+d.line, j+d.column) != empty {
+					result = append(result, position{i, j})
+					break
+				}
+			}
+		}
+	}
+	return result
+}
+
+// flipCost evaluates how much flipping the pixel alters the image: a pixel on
+// the border or next to a pixel of the other value is on the edge of a shape,
+// and the farther its gray level from the threshold, the more visible its flip
+func flipCost(cells [][]bool, contrast [][]uint8, p position) float64 {
+	cost := 2.0
+	for _, d := range []position{{-1, 0}, {1, 0}, {0, -1}, {0, 1}} {
+		n := position{p.line + d.line, p.column + d.column}
+		if n.line < 0 || n.line >= len(cells) || n.column < 0 || n.column >= len(cells[0]) ||
+			cells[n.line][n.column] != cells[p.line][p.column] {
+			cost = 1
+			break
+		}
+	}
+	if contrast != nil {
+		cost += float64(contrast[p.line][p.column]) / 64
+	}
+	return cost
+}
+
+// sameSize indicates if the contrast matrix has the size of the image
+func sameSize(cells [][]bool, contrast [][]uint8) bool {
+	if len(cells) != len(contrast) {
+		return false
+	}
+	for i := range cells {
+		if len(cells[i]) != len(contrast[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func copyCells(cells [][]bool) [][]bool {
+	result := make([][]bool, len(cells))
+	for i, row := range cells {
+		result[i] = append([]bool(nil), row...)
+	}
+	return result
+}
+
+// Contrast returns for each pixel of the image the distance of its gray level
+// to the threshold, to be used with Bitmap and Repair
+func Contrast(img image.Image, threshold uint8) [][]uint8 {
+	bounds := img.Bounds()
+	result := make([][]uint8, bounds.Dy())
+	for i := range result {
+		result[i] = make([]uint8, bounds.Dx())
+		for j := range result[i] {
+			y := int(grayLevel(img.At(bounds.Min.X+j, bounds.Min.Y+i)))
+			result[i][j] = uint8(max(y-int(threshold), int(threshold)-y))
+		}
+	}
+	return result
+}
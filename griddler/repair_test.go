@@ -0,0 +1,70 @@
+package griddler
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func randomCells(width, height int, density float64, seed int64) [][]bool {
+	r := rand.New(rand.NewSource(seed))
+	cells := make([][]bool, height)
+	for i := range cells {
+		cells[i] = make([]bool, width)
+		for j := range cells[i] {
+			cells[i][j] = r.Float64() < density
+		}
+	}
+	return cells
+}
+
+func TestRepair(t *testing.T) {
+	for seed := int64(1); seed <= 3; seed++ {
+		cells := randomCells(30, 30, 0.5, seed)
+		r, err := Repair(cells, RepairOptions{})
+		if err != nil {
+			t.Fatalf("seed %d: %v", seed, err)
+		}
+
+		// the repaired image differs from the original by the flips only
+		flipped := map[position]bool{}
+		for _, f := range r.Flips {
+			p := position{f.Line, f.Column}
+			if flipped[p] || r.Cells[p.line][p.column] != f.Filled || cells[p.line][p.column] == f.Filled {
+				t.Errorf("seed %d: unexpected flip %+v", seed, f)
+			}
+			flipped[p] = true
+		}
+		for i := range cells {
+			for j := range cells[i] {
+				if (cells[i][j] != r.Cells[i][j]) != flipped[position{i, j}] {
+					t.Errorf("seed %d: the pixel %d,%d differs from the flips", seed, i+1, j+1)
+				}
+			}
+		}
+
+		// and its puzzle is solved by logic alone
+		res, err := r.Griddler.Solve()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !res.Solved || res.Trials > 0 || res.Probed > 0 {
+			t.Errorf("seed %d: not solved by logic: %+v", seed, res)
+		}
+		if !r.Griddler.Solution().Equal(r.Griddler.Goal()) {
+			t.Errorf("seed %d: the solution differs from the repaired image", seed)
+		}
+		t.Logf("seed %d: %d flips", seed, len(r.Flips))
+	}
+}
+
+func TestRepairTimeout(t *testing.T) {
+	cells := randomCells(30, 30, 0.5, 3)
+	if _, err := Repair(cells, RepairOptions{Timeout: time.Nanosecond}); !errors.Is(err, ErrRepairTimedOut) {
+		t.Errorf("got %v, expected a timeout", err)
+	}
+	if _, err := Repair(cells, RepairOptions{MaxFlips: 1}); !errors.Is(err, ErrRepairFailed) {
+		t.Errorf("got %v, expected a failure", err)
+	}
+}
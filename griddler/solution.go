@@ -0,0 +1,217 @@
+package griddler
+
+import (
+	"bytes"
+	"container/heap"
+)
+
+// Solution is a snapshot of a completed board which can be compared with another one
+type Solution struct {
+	width, height int
+	values        []int
+	colors        []int
+}
+
+func newSolution(width, height int) *Solution {
+	return &Solution{
+		width:  width,
+		height: height,
+		values: make([]int, width*height),
+		colors: make([]int, width*height),
+	}
+}
+
+// set defines the square at the given 0-based line and column from its palette index
+func (s *Solution) set(line, column, color int) {
+	s.values[line*s.width+column] = valueOf(color)
+	s.colors[line*s.width+column] = color
+}
+
+// Width returns the number of columns of the solution
+func (s *Solution) Width() int {
+	return s.width
+}
+
+// Height returns the number of lines of the solution
+func (s *Solution) Height() int {
+	return s.height
+}
+
+// Value returns the value of the square at the given 0-based line and column
+func (s *Solution) Value(line, column int) int {
+	return s.values[line*s.width+column]
+}
+
+// Color returns the palette index of the square at the given 0-based line and
+// column, see Griddler.Colors
+func (s *Solution) Color(line, column int) int {
+	return s.colors[line*s.width+column]
+}
+
+// state returns the palette index of the square, -1 if it is empty
+func (s *Solution) state(line, column int) int {
+	if s.Value(line, column) == EMPTY {
+		return -1
+	}
+	return s.Color(line, column)
+}
+
+// sameState indicates if the square has the same state in all the solutions
+func sameState(ss []*Solution, line, column int) bool {
+	for _, s := range ss[1:] {
+		if s.state(line, column) != ss[0].state(line, column) {
+			return false
+		}
+	}
+	return true
+}
+
+// Filled indicates if the square at the given 0-based line and column is filled
+func (s *Solution) Filled(line, column int) bool {
+	return s.Value(line, column) == FILLED
+}
+
+// Cells returns the filled squares of the solution as a matrix, see FromSolution
+func (s *Solution) Cells() [][]bool {
+	cells := make([][]bool, s.height)
+	for i := range cells {
+		cells[i] = make([]bool, s.width)
+		for j := range cells[i] {
+			cells[i][j] = s.Filled(i, j)
+		}
+	}
+	return cells
+}
+
+// Equal reports whether both solutions have the same size and values
+func (s *Solution) Equal(o *Solution) bool {
+	if s.width != o.width || s.height != o.height {
+		return false
+	}
+	for i, v := range s.values {
+		if o.values[i] != v || o.colors[i] != s.colors[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Solution) String() string {
+	var b bytes.Buffer
+	for i := 0; i < s.height; i++ {
+		for j := 0; j < s.width; j++ {
+			if s.Filled(i, j) {
+				b.WriteByte('X')
+			} else {
+				b.WriteByte('.')
+			}
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// Solution returns the current board as a Solution, nil if it is not completed
+func (g *Griddler) Solution() *Solution {
+	if !g.isDone() {
+		return nil
+	}
+	return g.snapshot()
+}
+
+func (g *Griddler) snapshot() *Solution {
+	s := newSolution(g.width, g.height)
+	for i, l := range g.lines {
+		for j, sq := range l.squares {
+			s.values[i*g.width+j] = sq.value
+			s.colors[i*g.width+j] = sq.color
+		}
+	}
+	return s
+}
+
+// Solutions searches by backtracking the distinct solutions of the griddler, the
+// search stops as soon as limit solutions have been found (no limit if limit <= 0).
+// The search is bounded by the TrialTimeout option too, the solutions found so
+// far being then returned with ErrSearchTimedOut. The griddler is left in the
+// state it was before the search, the deductions of the initialization
+// included.
+func (g *Griddler) Solutions(limit int) ([]*Solution, error) {
+	if err := g.opts.check(); err != nil {
+		return nil, err
+	}
+	if err := g.Validate(); err != nil {
+		return nil, err
+	}
+	defer g.keep()()
+	result := make([]*Solution, 0)
+	if err := g.solveInit(); err != nil {
+		// a contradiction at this stage means that there is no solution at all
+		return result, nil
+	}
+
+	g.setDeadline()
+	g.phase = PhaseSearch
+	g.emit(Event{Kind: PhaseStart})
+	if !g.searchSolutions(&result, limit) {
+		return result, ErrSearchTimedOut
+	}
+	return result, nil
+}
+
+// CountSolutions returns the number of solutions of the griddler, counting up to
+// limit (no limit if limit <= 0), e.g. a limit of 2 is enough to check uniqueness.
+// As for Solutions, the count is incomplete when ErrSearchTimedOut is returned.
+func (g *Griddler) CountSolutions(limit int) (int, error) {
+	sols, err := g.Solutions(limit)
+	return len(sols), err
+}
+
+// searchSolutions adds the solutions reachable from the current board to the
+// result, and returns false if the search was interrupted by the timeout
+func (g *Griddler) searchSolutions(result *[]*Solution, limit int) bool {
+	if g.isTimedOut() {
+		return false
+	}
+	if err := g.solveByLogic(); err != nil {
+		g.emit(Event{Kind: Contradiction, Err: err})
+		return true
+	}
+	if g.isDone() {
+		*result = append(*result, g.snapshot())
+		return true
+	}
+
+	s := g.nextCandidate()
+	saved := g.save()
+	for _, v := range g.states(s.Square, s.pvalue) {
+		g.SetColor(s.Square, v)
+		complete := g.searchSolutions(result, limit)
+		g.restore(saved)
+		if !complete {
+			return false
+		}
+		if limit > 0 && len(*result) >= limit {
+			return true
+		}
+	}
+	return true
+}
+
+// nextCandidate returns the empty square with the highest trial priority,
+// or the first empty square if none has been selected
+func (g *Griddler) nextCandidate() *PrioSquare {
+	pq := make(prioQueue, 0)
+	g.populateForTrial(&pq)
+	if pq.Len() > 0 {
+		return heap.Pop(&pq).(*PrioSquare)
+	}
+	for _, l := range g.lines {
+		for _, s := range l.squares {
+			if s.value == EMPTY {
+				return &PrioSquare{s, g.firstColor(s), 0}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package griddler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCountSolutions(t *testing.T) {
+	tests := []struct {
+		name     string
+		def      string
+		limit    int
+		expected int
+	}{
+		{"unique", "2x2\nH:1;2\nH:2;1\nV:1;2\nV:2;1\n", 0, 1},
+		{"diagonals", "2x2\nH:1;1\nH:2;1\nV:1;1\nV:2;1\n", 0, 2},
+		{"contradictory", "3x1\nH:1;1,1\nV:1;1\nV:2;1\nV:3;0\n", 0, 0},
+		{"permutations", "4x4\nH:1;1\nH:2;1\nH:3;1\nH:4;1\nV:1;1\nV:2;1\nV:3;1\nV:4;1\n", 0, 24},
+		{"limited", "4x4\nH:1;1\nH:2;1\nH:3;1\nH:4;1\nV:1;1\nV:2;1\nV:3;1\nV:4;1\n", 5, 5},
+		{"colored", "2x2\nC:r;ff0000\nH:1;1,1r\nH:2;1r,1\nV:1;1,1r\nV:2;1r,1\n", 0, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := New(SolverOptions{})
+			if err := g.Parse([]byte(tt.def)); err != nil {
+				t.Fatal(err)
+			}
+			n, err := g.CountSolutions(tt.limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != tt.expected {
+				t.Errorf("got %d solutions, expected %d", n, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCountSolutionsTimeout(t *testing.T) {
+	// the permutations of 10 squares are far too many to be counted in time
+	var def strings.Builder
+	def.WriteString("10x10\n")
+	for i := 1; i <= 10; i++ {
+		fmt.Fprintf(&def, "H:%d;1\nV:%d;1\n", i, i)
+	}
+	g := New(SolverOptions{TrialTimeout: time.Millisecond})
+	if err := g.Parse([]byte(def.String())); err != nil {
+		t.Fatal(err)
+	}
+	n, err := g.CountSolutions(0)
+	if !errors.Is(err, ErrSearchTimedOut) {
+		t.Fatalf("got %d solutions and %v, expected a timeout", n, err)
+	}
+}
+
+func TestSolutionsKeepState(t *testing.T) {
+	// the overlaps of the initialization find squares, which must be undone
+	def := []byte("3x3\nH:1;3\nH:2;1\nH:3;1,1\nV:1;1,1\nV:2;2\nV:3;1,1\n")
+	g := New(SolverOptions{})
+	if err := g.Parse(def); err != nil {
+		t.Fatal(err)
+	}
+	var before, after bytes.Buffer
+	if err := g.SaveState(&before); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.CountSolutions(0); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.SaveState(&after); err != nil {
+		t.Fatal(err)
+	}
+	if before.String() != after.String() {
+		t.Errorf("state changed by the search:\n%s\n%s", before.String(), after.String())
+	}
+	if g.isInit || g.found != 0 {
+		t.Errorf("solving progress changed by the search: init %t, %d squares found", g.isInit, g.found)
+	}
+}
+
+// runLengths returns the lengths of the runs of filled cells
+func runLengths(cells []bool) []int {
+	result := make([]int, 0)
+	for _, c := range cluesOf(cells) {
+		result = append(result, c.length)
+	}
+	return result
+}
+
+func sameLengths(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// bruteForceCount counts the grids of the given size having the clues of cells
+func bruteForceCount(cells [][]bool) int {
+	height, width := len(cells), len(cells[0])
+	rows := make([][]int, height)
+	for i := range cells {
+		rows[i] = runLengths(cells[i])
+	}
+	columns := make([][]int, width)
+	column := make([]bool, height)
+	for j := range columns {
+		for i := range cells {
+			column[i] = cells[i][j]
+		}
+		columns[j] = runLengths(column)
+	}
+
+	count := 0
+	grid := make([][]bool, height)
+	for i := range grid {
+		grid[i] = make([]bool, width)
+	}
+	for bits := 0; bits < 1<<uint(width*height); bits++ {
+		valid := true
+		for i := 0; i < height && valid; i++ {
+			for j := 0; j < width; j++ {
+				grid[i][j] = bits&(1<<uint(i*width+j)) != 0
+			}
+			valid = sameLengths(runLengths(grid[i]), rows[i])
+		}
+		for j := 0; j < width && valid; j++ {
+			for i := range grid {
+				column[i] = grid[i][j]
+			}
+			valid = sameLengths(runLengths(column), columns[j])
+		}
+		if valid {
+			count++
+		}
+	}
+	return count
+}
+
+func TestCountSolutionsRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for n := 0; n < 20; n++ {
+		width, height := 2+r.Intn(3), 2+r.Intn(3)
+		cells := make([][]bool, height)
+		for i := range cells {
+			cells[i] = make([]bool, width)
+			for j := range cells[i] {
+				cells[i][j] = r.Intn(2) == 0
+			}
+		}
+		g, err := FromSolution(cells)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := g.CountSolutions(0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected := bruteForceCount(cells); got != expected {
+			t.Errorf("%v: got %d solutions, expected %d", cells, got, expected)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package griddler
 
 import (
 	"fmt"
+	"io"
 )
 
 const (
@@ -13,33 +14,63 @@ const (
 // Square is the basic element of the grid, it inherits val from Tile
 type Square struct {
 	Tile
-	x, y int
+	x, y   int
+	color  int    // palette index of the square once found, the background for a blank one
+	colors uint64 // palette indexes still possible for an empty square, 0 if not restricted
 }
 
 func NewSquare(x, y, v int) *Square {
-	return &Square{
-		Tile{v},
-		x,
-		y,
+	s := &Square{
+		Tile: Tile{v},
+		x:    x,
+		y:    y,
 	}
+	if v == FILLED {
+		s.color = defaultColor
+	}
+	return s
+}
+
+// state returns the palette index of the square, -1 if it is still empty
+func (s *Square) state() int {
+	if s.value == EMPTY {
+		return -1
+	}
+	return s.color
+}
+
+// canBe indicates if the square can take the given palette index
+func (s *Square) canBe(color int) bool {
+	if s.value != EMPTY {
+		return s.color == color
+	}
+	return s.colors == 0 || s.colors&(1<<uint(color)) != 0
+}
+
+// valueOf returns the value of a square having the given palette index
+func valueOf(color int) int {
+	if color == backgroundColor {
+		return BLANK
+	}
+	return FILLED
 }
 
-func (s Square) show() {
+func (s Square) show(w io.Writer) {
 	//fmt.Printf("(%d,%d,", s.x, s.y)
 	switch s.value {
 	case EMPTY:
-		fmt.Printf(" ")
+		fmt.Fprintf(w, " ")
 	case BLANK:
-		fmt.Printf(".")
+		fmt.Fprintf(w, ".")
 	case FILLED:
-		fmt.Printf("X")
+		fmt.Fprintf(w, "X")
 	}
 	//fmt.Printf(")")
 }
 
 type PrioSquare struct {
 	*Square
-	pvalue   int
+	pvalue   int // palette index to try first
 	priority int
 }
 
@@ -0,0 +1,320 @@
+package griddler
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// The state file stores a griddler being solved or played: its definition in
+// the native format, the value of every square ('?' for an empty one, '.' for a
+// blank one and 'X' for a filled one, or the name of its color, which can not
+// be 'X'), then the colors still possible for the empty squares of a colored
+// griddler when they are restricted, by line and column, and, once the solving
+// has started, the indexes of the first and last unsolved clues and the limits
+// of every clue:
+//
+//	#state
+//	5x3
+//	H:1;2
+//	...
+//	#squares
+//	?XX..
+//	...
+//	#colors
+//	1,1;.r
+//	...
+//	#clues
+//	H:1;1,1;2-3
+//	...
+const (
+	stateHeader  = "#state"
+	stateSquares = "#squares"
+	stateColors  = "#colors"
+	stateClues   = "#clues"
+)
+
+var squareChars = map[int]byte{EMPTY: '?', BLANK: '.', FILLED: 'X'}
+
+// SaveState writes the griddler and its current solving state
+func (g *Griddler) SaveState(w io.Writer) error {
+	if g.isTriddler {
+		return ErrUnsupportedPuzzle
+	}
+	var b bytes.Buffer
+	fmt.Fprintln(&b, stateHeader)
+	if err := g.Save(&b); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(&b, stateSquares)
+	restricted := make([](*Square), 0)
+	for _, l := range g.lines {
+		for _, s := range l.squares {
+			if s.value != EMPTY {
+				b.WriteString(g.colorChar(s.color))
+				continue
+			}
+			if s.colors != 0 {
+				restricted = append(restricted, s)
+			}
+			b.WriteByte(squareChars[EMPTY])
+		}
+		b.WriteByte('\n')
+	}
+
+	if len(restricted) > 0 {
+		fmt.Fprintln(&b, stateColors)
+		for _, s := range restricted {
+			fmt.Fprintf(&b, "%d,%d;", s.x+1, s.y+1)
+			for color := range g.palette {
+				if s.canBe(color) {
+					b.WriteString(g.colorChar(color))
+				}
+			}
+			b.WriteByte('\n')
+		}
+	}
+
+	// the clue limits are only meaningful once initialized by the solver
+	if g.isInit {
+		fmt.Fprintln(&b, stateClues)
+		for i, l := range g.lines {
+			fmt.Fprintf(&b, "H:%d;%s\n", i+1, l.limitsString())
+		}
+		for i, c := range g.columns {
+			fmt.Fprintf(&b, "V:%d;%s\n", i+1, c.limitsString())
+		}
+	}
+
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// LoadState reads a griddler saved by SaveState, the solving can then be resumed
+func (g *Griddler) LoadState(r io.Reader) error {
+	scanner := newScanner(r)
+	line := 0
+	next := func() (string, bool) {
+		if !scanner.Scan() {
+			return "", false
+		}
+		line++
+		return strings.TrimSpace(scanner.Text()), true
+	}
+
+	if text, ok := next(); !ok || text != stateHeader {
+		return g.error(ErrInvalidStateFormat, 1)
+	}
+
+	// the griddler definition goes up to the squares section
+	var def bytes.Buffer
+	text, ok := next()
+	for ok && text != stateSquares {
+		fmt.Fprintln(&def, text)
+		text, ok = next()
+	}
+	if !ok {
+		return g.error(ErrInvalidStateFormat, line)
+	}
+	if err := g.LoadFrom(&def); err != nil {
+		// the definition starts on the second line of the state file
+		switch perr := err.(type) {
+		case *ParseError:
+			perr.line++
+		case ParseErrors:
+			for _, e := range perr {
+				e.line++
+			}
+		}
+		return err
+	}
+
+	for i := 0; i < g.height; i++ {
+		text, ok = next()
+		if !ok || len(text) != g.width {
+			return g.error(ErrInvalidStateFormat, line)
+		}
+		for j := 0; j < g.width; j++ {
+			s := g.lines[i].squares[j]
+			if text[j] == '?' {
+				s.value = EMPTY
+				continue
+			}
+			color := g.charColor(text[j])
+			if color < 0 {
+				return g.error(ErrInvalidStateFormat, line)
+			}
+			s.value, s.color = valueOf(color), color
+		}
+	}
+
+	// the optional sections follow, in order
+	text, ok = next()
+	if ok && text == stateColors {
+		for text, ok = next(); ok && text != stateClues; text, ok = next() {
+			if text == "" {
+				continue
+			}
+			if err := g.parseColors(text); err != nil {
+				return g.error(err, line)
+			}
+		}
+	}
+	if ok && text == stateClues {
+		for text, ok = next(); ok; text, ok = next() {
+			if text == "" {
+				continue
+			}
+			if err := g.parseLimits(text); err != nil {
+				return g.error(err, line)
+			}
+		}
+		g.isInit = true
+	}
+
+	g.recount()
+	return nil
+}
+
+// parseLimits reads the line H:i;cb,ce;b-e,b-e,... (1-based indexes)
+func (g *Griddler) parseLimits(text string) error {
+	tokens := strings.Split(text, ";")
+	if len(tokens) != 3 {
+		return ErrMissingSemiColon
+	}
+	infos := strings.Split(tokens[0], ":")
+	if len(infos) != 2 {
+		return ErrInvalidTokenLine
+	}
+	index, err := strconv.Atoi(infos[1])
+	if err != nil {
+		return ErrInvalidIntLine
+	}
+	var l *Line
+	switch {
+	case infos[0] == "H" && index >= 1 && index <= g.height:
+		l = g.lines[index-1]
+	case infos[0] == "V" && index >= 1 && index <= g.width:
+		l = g.columns[index-1]
+	default:
+		return ErrInvalidTokenLine
+	}
+
+	// the first unsolved clue follows the last one once they are all solved
+	indexes, err := parseInts(strings.Split(tokens[1], ","))
+	if err != nil || len(indexes) != 2 || indexes[0] < 1 || indexes[0] > indexes[1]+1 || indexes[1] > len(l.clues) {
+		return ErrInvalidStateFormat
+	}
+	l.cb, l.ce = indexes[0]-1, indexes[1]-1
+
+	limits := strings.Split(tokens[2], ",")
+	if tokens[2] == "" {
+		limits = nil
+	}
+	if len(limits) != len(l.clues) {
+		return ErrInvalidStateFormat
+	}
+	for i, limit := range limits {
+		bounds, err := parseInts(strings.Split(limit, "-"))
+		if err != nil || len(bounds) != 2 || bounds[0] < 1 || bounds[0] > bounds[1] || bounds[1] > l.length {
+			return ErrInvalidStateFormat
+		}
+		l.clues[i].begin, l.clues[i].end = bounds[0]-1, bounds[1]-1
+	}
+	return nil
+}
+
+// parseColors reads the line i,j;cc... giving the colors still possible for
+// the empty square of the line i and the column j (1-based indexes)
+func (g *Griddler) parseColors(text string) error {
+	tokens := strings.Split(text, ";")
+	if len(tokens) != 2 {
+		return ErrMissingSemiColon
+	}
+	position, err := parseInts(strings.Split(tokens[0], ","))
+	if err != nil || len(position) != 2 || position[0] < 1 || position[0] > g.height || position[1] < 1 || position[1] > g.width {
+		return ErrInvalidStateFormat
+	}
+	s := g.lines[position[0]-1].squares[position[1]-1]
+	if s.value != EMPTY || tokens[1] == "" {
+		return ErrInvalidStateFormat
+	}
+	for i := range tokens[1] {
+		color := g.charColor(tokens[1][i])
+		if color < 0 {
+			return ErrInvalidStateFormat
+		}
+		s.colors |= 1 << uint(color)
+	}
+	return nil
+}
+
+// colorChar returns the char of a palette index in a state file
+func (g *Griddler) colorChar(color int) string {
+	switch color {
+	case backgroundColor:
+		return string(squareChars[BLANK])
+	case defaultColor:
+		return string(squareChars[FILLED])
+	}
+	return g.palette[color].Name
+}
+
+// charColor returns the palette index of a char of a state file, -1 if it
+// is not a color
+func (g *Griddler) charColor(ch byte) int {
+	switch ch {
+	case squareChars[BLANK]:
+		return backgroundColor
+	case squareChars[FILLED]:
+		return defaultColor
+	}
+	if color := g.colorIndex(string(ch)); color > defaultColor {
+		return color
+	}
+	return -1
+}
+
+func parseInts(fields []string) ([]int, error) {
+	result := make([]int, len(fields))
+	for i, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil {
+			return nil, ErrInvalidIntValue
+		}
+		result[i] = n
+	}
+	return result, nil
+}
+
+// limitsString returns the unsolved clue indexes and the clue limits, 1-based
+func (l *Line) limitsString() string {
+	limits := make([]string, len(l.clues))
+	for i, c := range l.clues {
+		limits[i] = fmt.Sprintf("%d-%d", c.begin+1, c.end+1)
+	}
+	return fmt.Sprintf("%d,%d;%s", l.cb+1, l.ce+1, strings.Join(limits, ","))
+}
+
+// recount updates the line counters from the square values, and schedules all
+// the lines to be checked again by the solver
+func (g *Griddler) recount() {
+	for _, ls := range g.axes {
+		for _, l := range ls {
+			g.pushLine(l)
+			l.sumBlanks, l.sumClues = 0, 0
+			for _, s := range l.squares {
+				switch s.value {
+				case BLANK:
+					l.sumBlanks++
+				case FILLED:
+					l.sumClues++
+				}
+			}
+			l.isDone = l.sumBlanks+l.sumClues == l.length
+		}
+	}
+}
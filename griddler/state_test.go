@@ -0,0 +1,138 @@
+package griddler
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStateRoundTrip(t *testing.T) {
+	g := New(SolverOptions{})
+	if err := g.Parse([]byte("3x2\nC:r;ff0000\nH:1;1,1r\nH:2;1r,1\nV:1;1,1r\nV:2;0\nV:3;1r,1\n")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.Solve(); err != nil {
+		t.Fatal(err)
+	}
+	var first bytes.Buffer
+	if err := g.SaveState(&first); err != nil {
+		t.Fatal(err)
+	}
+	loaded := New(SolverOptions{})
+	if err := loaded.LoadState(bytes.NewReader(first.Bytes())); err != nil {
+		t.Fatalf("%v\n%s", err, first.String())
+	}
+	var second bytes.Buffer
+	if err := loaded.SaveState(&second); err != nil {
+		t.Fatal(err)
+	}
+	if first.String() != second.String() {
+		t.Errorf("round trip differs:\n%s\n%s", first.String(), second.String())
+	}
+}
+
+func TestReservedColorName(t *testing.T) {
+	g := New(SolverOptions{})
+	if err := g.Parse([]byte("2x1\nC:X;ff0000\nH:1;1X,1\nV:1;1X\nV:2;1\n")); !errors.Is(err, ErrInvalidColorName) {
+		t.Errorf("color X: expected %v, got %v", ErrInvalidColorName, err)
+	}
+}
+
+// checkStateRoundTrip saves the state of the griddler, loads it and checks
+// that saving it again gives the same content
+func checkStateRoundTrip(t *testing.T, g *Griddler) *Griddler {
+	t.Helper()
+	var first bytes.Buffer
+	if err := g.SaveState(&first); err != nil {
+		t.Fatal(err)
+	}
+	loaded := New(SolverOptions{})
+	if err := loaded.LoadState(bytes.NewReader(first.Bytes())); err != nil {
+		t.Fatalf("%v\n%s", err, first.String())
+	}
+	var second bytes.Buffer
+	if err := loaded.SaveState(&second); err != nil {
+		t.Fatal(err)
+	}
+	if first.String() != second.String() {
+		t.Errorf("round trip differs:\n%s\n%s", first.String(), second.String())
+	}
+	return loaded
+}
+
+func TestStatePartialSolve(t *testing.T) {
+	files, err := filepath.Glob("../data/*.grid*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		t.Run(filepath.Base(f), func(t *testing.T) {
+			data, err := os.ReadFile(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// the overlaps alone leave most puzzles partially solved
+			g := New(SolverOptions{Algorithms: []string{"solveFilledRanges"}})
+			if err := g.Parse(data); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := g.Solve(); err != nil {
+				t.Fatal(err)
+			}
+			checkStateRoundTrip(t, g)
+		})
+	}
+}
+
+func TestStateColors(t *testing.T) {
+	g := New(SolverOptions{})
+	if err := g.Parse([]byte("3x1\nC:r;ff0000\nC:g;00ff00\nH:1;1r,1g\nV:1;1r\nV:2;0\nV:3;1g\n")); err != nil {
+		t.Fatal(err)
+	}
+	s := g.lines[0].squares[0]
+	g.restrict(s, 1<<backgroundColor|1<<2)
+	loaded := checkStateRoundTrip(t, g)
+	if mask := loaded.possible(loaded.lines[0].squares[0]); mask != 1<<backgroundColor|1<<2 {
+		t.Errorf("colors of the square %b, expected %b", mask, 1<<backgroundColor|1<<2)
+	}
+	if mask := loaded.possible(loaded.lines[0].squares[1]); mask != loaded.allColors() {
+		t.Errorf("colors of the square %b, expected all of them", mask)
+	}
+}
+
+func TestInvalidState(t *testing.T) {
+	def := "#state\n10x1\nH:1;1,1\nV:1;1\nV:2;0\nV:3;1\nV:4;0\nV:5;0\nV:6;0\nV:7;0\nV:8;0\nV:9;0\nV:10;0\n#squares\n??????????\n"
+	tests := map[string]string{
+		"first clue too high":   "#clues\nH:1;5,9;1-3,3-5\n",
+		"last clue too high":    "#clues\nH:1;1,3;1-3,3-5\n",
+		"first clue zero":       "#clues\nH:1;0,2;1-3,3-5\n",
+		"first after last":      "#clues\nH:1;3,1;1-3,3-5\n",
+		"limit beyond the line": "#clues\nH:1;1,2;1-90,3-5\n",
+		"limit zero":            "#clues\nH:1;1,2;0-3,3-5\n",
+		"limits reversed":       "#clues\nH:1;1,2;3-1,3-5\n",
+		"colors of a blank":     "#colors\n1,1;.X\n",
+		"colors out of range":   "#colors\n2,1;.X\n",
+		"unknown color":         "#colors\n1,1;.r\n",
+	}
+	for name, section := range tests {
+		t.Run(name, func(t *testing.T) {
+			content := def + section
+			if name == "colors of a blank" {
+				content = strings.Replace(content, "??????????", ".?????????", 1)
+			}
+			g := New(SolverOptions{})
+			if err := g.LoadState(strings.NewReader(content)); !errors.Is(err, ErrInvalidStateFormat) {
+				t.Errorf("expected %v, got %v", ErrInvalidStateFormat, err)
+			}
+		})
+	}
+
+	// the same limits within range are accepted
+	g := New(SolverOptions{})
+	if err := g.LoadState(strings.NewReader(def + "#clues\nH:1;1,2;1-3,3-5\n")); err != nil {
+		t.Error(err)
+	}
+}
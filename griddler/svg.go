@@ -0,0 +1,141 @@
+package griddler
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+const defaultCellSize = 20
+
+// SVGOptions configures the SVG rendering of a griddler
+type SVGOptions struct {
+	CellSize int  // size of a square in pixels, 20 if 0
+	Solved   bool // draw the squares found so far, otherwise only the clues and an empty grid (printable version)
+}
+
+func (o *SVGOptions) cellSize() int {
+	if o.CellSize <= 0 {
+		return defaultCellSize
+	}
+	return o.CellSize
+}
+
+// WriteSVG draws the griddler with its clue headers as an SVG document, the
+// triddlers being not supported
+func (g *Griddler) WriteSVG(w io.Writer, opts SVGOptions) error {
+	if g.isTriddler {
+		return ErrUnsupportedPuzzle
+	}
+	cs := opts.cellSize()
+	left := maxClues(g.lines) * cs
+	top := maxClues(g.columns) * cs
+	width := left + g.width*cs
+	height := top + g.height*cs
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\">\n", width+1, height+1, width+1, height+1)
+	fmt.Fprintf(&b, "<rect width=\"100%%\" height=\"100%%\" fill=\"white\"/>\n")
+
+	// clue headers, aligned on the grid
+	fmt.Fprintf(&b, "<g font-family=\"sans-serif\" font-size=\"%d\" text-anchor=\"middle\" dominant-baseline=\"central\">\n", cs*3/5)
+	for i, l := range g.lines {
+		clues := displayedClues(l)
+		for k, c := range clues {
+			x := left - (len(clues)-k)*cs + cs/2
+			y := top + i*cs + cs/2
+			fmt.Fprintf(&b, "<text x=\"%d\" y=\"%d\"%s>%s</text>\n", x, y, g.svgFill(c.color), c.text)
+		}
+	}
+	for j, col := range g.columns {
+		clues := displayedClues(col)
+		for k, c := range clues {
+			x := left + j*cs + cs/2
+			y := top - (len(clues)-k)*cs + cs/2
+			fmt.Fprintf(&b, "<text x=\"%d\" y=\"%d\"%s>%s</text>\n", x, y, g.svgFill(c.color), c.text)
+		}
+	}
+	fmt.Fprintf(&b, "</g>\n")
+
+	// squares
+	if opts.Solved {
+		for i, l := range g.lines {
+			for j, s := range l.squares {
+				x, y := left+j*cs, top+i*cs
+				switch s.value {
+				case FILLED:
+					fill := " fill=\"black\""
+					if s.color != defaultColor {
+						fill = g.svgFill(s.color)
+					}
+					fmt.Fprintf(&b, "<rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\"%s/>\n", x, y, cs, cs, fill)
+				case BLANK:
+					fmt.Fprintf(&b, "<circle cx=\"%d\" cy=\"%d\" r=\"%d\" fill=\"gray\"/>\n", x+cs/2, y+cs/2, max(cs/10, 1))
+				}
+			}
+		}
+	}
+
+	// grid, with a thicker line every 5 squares
+	fmt.Fprintf(&b, "<g stroke=\"black\">\n")
+	for i := 0; i <= g.height; i++ {
+		fmt.Fprintf(&b, "<line x1=\"0\" y1=\"%d\" x2=\"%d\" y2=\"%d\" stroke-width=\"%s\"/>\n", top+i*cs, width, top+i*cs, strokeWidth(i, g.height))
+	}
+	for j := 0; j <= g.width; j++ {
+		fmt.Fprintf(&b, "<line x1=\"%d\" y1=\"0\" x2=\"%d\" y2=\"%d\" stroke-width=\"%s\"/>\n", left+j*cs, left+j*cs, height, strokeWidth(j, g.width))
+	}
+	fmt.Fprintf(&b, "</g>\n")
+	fmt.Fprintf(&b, "</svg>\n")
+
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+func strokeWidth(i, n int) string {
+	if i%5 == 0 || i == n {
+		return "2"
+	}
+	return "0.5"
+}
+
+// svgFill returns the fill attribute of the given palette index, nothing for
+// the default color
+func (g *Griddler) svgFill(color int) string {
+	if color == defaultColor {
+		return ""
+	}
+	return fmt.Sprintf(" fill=\"#%s\"", hexRGB(g.palette[color].RGB))
+}
+
+// clueLabel is a clue as displayed in the headers
+type clueLabel struct {
+	text  string
+	color int
+}
+
+// displayedClues returns the clues to display for the line, "0" for an empty
+// one and "*" if its clues are hidden
+func displayedClues(l *Line) []clueLabel {
+	labels := make([]clueLabel, 0, len(l.clues))
+	for _, c := range l.clues {
+		if c.length > 0 {
+			labels = append(labels, clueLabel{c.label(), c.color})
+		}
+	}
+	switch {
+	case l.isHidden():
+		labels = append(labels, clueLabel{hiddenClues, defaultColor})
+	case len(labels) == 0:
+		labels = append(labels, clueLabel{"0", defaultColor})
+	}
+	return labels
+}
+
+// maxClues returns the highest number of clues to display among the lines
+func maxClues(ls [](*Line)) int {
+	result := 1
+	for _, l := range ls {
+		result = max(result, len(displayedClues(l)))
+	}
+	return result
+}
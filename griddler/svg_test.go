@@ -0,0 +1,106 @@
+package griddler
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"testing"
+)
+
+// svgDocument is the content of an SVG document written by WriteSVG
+type svgDocument struct {
+	Width   int        `xml:"width,attr"`
+	Height  int        `xml:"height,attr"`
+	Texts   []svgText  `xml:"g>text"`
+	Rects   []svgRect  `xml:"rect"`
+	Circles []struct{} `xml:"circle"`
+	Lines   []struct{} `xml:"g>line"`
+}
+
+type svgText struct {
+	X    int    `xml:"x,attr"`
+	Y    int    `xml:"y,attr"`
+	Fill string `xml:"fill,attr"`
+	Text string `xml:",chardata"`
+}
+
+type svgRect struct {
+	Fill string `xml:"fill,attr"`
+}
+
+func parseSVG(t *testing.T, g *Griddler, opts SVGOptions) svgDocument {
+	t.Helper()
+	var b bytes.Buffer
+	if err := g.WriteSVG(&b, opts); err != nil {
+		t.Fatal(err)
+	}
+	var doc svgDocument
+	if err := xml.Unmarshal(b.Bytes(), &doc); err != nil {
+		t.Fatalf("%v\n%s", err, b.String())
+	}
+	return doc
+}
+
+func TestWriteSVG(t *testing.T) {
+	g := New(SolverOptions{})
+	if err := g.Parse([]byte("3x2\nC:r;ff0000\nH:1;1,1r\nH:2;2\nV:1;1,1\nV:2;0\nV:3;1r,1\n")); err != nil {
+		t.Fatal(err)
+	}
+	g.SetValue(g.lines[0].squares[0], FILLED)
+	g.SetColor(g.lines[0].squares[2], 2)
+	g.SetValue(g.lines[0].squares[1], BLANK)
+
+	for _, solved := range []bool{false, true} {
+		doc := parseSVG(t, g, SVGOptions{CellSize: 10, Solved: solved})
+		// 2 clues at most on a line and on a column, of 10 pixels
+		if doc.Width != 2*10+3*10+1 || doc.Height != 2*10+2*10+1 {
+			t.Errorf("solved %t: size %dx%d", solved, doc.Width, doc.Height)
+		}
+		texts := make([]string, len(doc.Texts))
+		for i, text := range doc.Texts {
+			texts[i] = text.Text
+		}
+		expected := []string{"1", "1", "2", "1", "1", "0", "1", "1"}
+		if len(texts) != len(expected) {
+			t.Fatalf("solved %t: clues %v, expected %v", solved, texts, expected)
+		}
+		for i := range expected {
+			if texts[i] != expected[i] {
+				t.Errorf("solved %t: clues %v, expected %v", solved, texts, expected)
+				break
+			}
+		}
+		if doc.Texts[1].Fill != "#ff0000" || doc.Texts[0].Fill != "" {
+			t.Errorf("solved %t: colors of the clues %q and %q", solved, doc.Texts[0].Fill, doc.Texts[1].Fill)
+		}
+		// the clue of the second line is right-aligned on the grid
+		if doc.Texts[2].X != 15 || doc.Texts[2].Y != 35 {
+			t.Errorf("solved %t: clue at %d,%d", solved, doc.Texts[2].X, doc.Texts[2].Y)
+		}
+		if len(doc.Lines) != 3+4 {
+			t.Errorf("solved %t: %d grid lines", solved, len(doc.Lines))
+		}
+
+		// the background, then the squares found when solved
+		rects, circles := 1, 0
+		if solved {
+			rects, circles = 3, 1
+		}
+		if len(doc.Rects) != rects || len(doc.Circles) != circles {
+			t.Errorf("solved %t: %d rects and %d circles, expected %d and %d", solved, len(doc.Rects), len(doc.Circles), rects, circles)
+		}
+		if solved && (doc.Rects[1].Fill != "black" || doc.Rects[2].Fill != "#ff0000") {
+			t.Errorf("colors of the squares %q and %q", doc.Rects[1].Fill, doc.Rects[2].Fill)
+		}
+	}
+}
+
+func TestWriteSVGTriddler(t *testing.T) {
+	g := New(SolverOptions{})
+	if err := g.Parse([]byte("triddler 2\nH:1;1\nH:2;3\nL:1;2\nL:2;1\nR:1;2\nR:2;1\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.WriteSVG(&bytes.Buffer{}, SVGOptions{}); !errors.Is(err, ErrUnsupportedPuzzle) {
+		t.Errorf("expected %v, got %v", ErrUnsupportedPuzzle, err)
+	}
+}
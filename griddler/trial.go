@@ -0,0 +1,204 @@
+package griddler
+
+import (
+	"container/heap"
+	"time"
+)
+
+// solveTrialRound tries the candidate squares by priority until one of them is
+// proven wrong, its opposite value (or, on a colored griddler, the remaining
+// colors) being then kept. With the two-sided probing, all the values of each
+// candidate are tried to learn the squares they agree on.
+// It returns false if no progress could be made, and an error if the board turns
+// out to be contradictory.
+func (g *Griddler) solveTrialRound(res *Result) (bool, error) {
+	saved := g.save()
+
+	pq := make(prioQueue, 0)
+	selected, potential, total := g.populateForTrial(&pq)
+	g.phase = PhaseTrial
+	g.emit(Event{Kind: PhaseStart, Selected: selected, Potential: potential, Total: total})
+
+	for attempt := 1; pq.Len() > 0; attempt++ {
+		if g.isTimedOut() {
+			res.TimedOut = true
+			return false, nil
+		}
+		s := heap.Pop(&pq).(*PrioSquare)
+		g.emit(Event{Kind: TrialStart, Line: s.x + 1, Column: s.y + 1, Value: valueOf(s.pvalue), Color: s.pvalue, Selected: selected, Attempt: attempt})
+		if g.opts.TwoSidedProbing {
+			found, err := g.probeSquare(s, saved, res)
+			if err != nil || found {
+				return found, err
+			}
+			continue
+		}
+		err := g.tryValue(s.Square, s.pvalue, g.opts.trialDepth())
+		res.Trials++
+		if err == nil && g.isDone() {
+			return true, nil
+		}
+		g.restore(saved)
+		if err != nil {
+			res.TrialsSuccess++
+			g.exclude(s.Square, s.pvalue)
+		}
+		g.emit(Event{Kind: TrialResult, Line: s.x + 1, Column: s.y + 1, Value: valueOf(s.pvalue), Color: s.pvalue, Selected: selected, Attempt: attempt, Err: err})
+		if err != nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// probeSquare tries every value of the candidate from the saved board: the
+// values leading to a contradiction are excluded, and when several values are
+// possible, the squares found with the same value in all outcomes are certain.
+// It returns true if some progress was made, and an error if all the values are
+// contradictory.
+func (g *Griddler) probeSquare(s *PrioSquare, saved *Griddler, res *Result) (bool, error) {
+	values := g.states(s.Square, s.pvalue)
+	outcomes := make([]*Solution, 0, len(values))
+	errs := make([]error, len(values))
+	for i, v := range values {
+		errs[i] = g.tryValue(s.Square, v, g.opts.trialDepth())
+		res.Trials++
+		if errs[i] == nil && g.isDone() {
+			return true, nil
+		}
+		if errs[i] == nil {
+			outcomes = append(outcomes, g.snapshot())
+		}
+		g.restore(saved)
+	}
+
+	switch {
+	case len(outcomes) == 0:
+		return false, errs[len(errs)-1]
+	case len(outcomes) < len(values):
+		res.TrialsSuccess++
+		for i, v := range values {
+			if errs[i] != nil {
+				g.exclude(s.Square, v)
+				g.emit(Event{Kind: TrialResult, Line: s.x + 1, Column: s.y + 1, Value: valueOf(v), Color: v, Err: errs[i]})
+			}
+		}
+		return true, nil
+	}
+
+	found := false
+	for i, l := range g.lines {
+		for j, sq := range l.squares {
+			v := outcomes[0].state(i, j)
+			if sq.value == EMPTY && v >= 0 && sameState(outcomes, i, j) {
+				g.SetColor(sq, v)
+				res.Probed++
+				found = true
+			}
+		}
+	}
+	g.emit(Event{Kind: TrialResult, Line: s.x + 1, Column: s.y + 1, Value: valueOf(s.pvalue), Color: s.pvalue})
+	return found, nil
+}
+
+// tryValue sets the hypothesis value (a palette index) on the square and looks for a contradiction.
+// When the logic is not enough and depth allows it, further hypotheses are made on
+// the candidates of the resulting board: a candidate whose value is contradictory
+// gets its opposite value, and the initial hypothesis is wrong as soon as this
+// opposite value is contradictory too. On success, the board is left completed or
+// in the state reached by the hypothesis.
+func (g *Griddler) tryValue(s *Square, value, depth int) error {
+	g.SetColor(s, value)
+	if err := g.solveByTrial(); err != nil {
+		return err
+	}
+	if depth <= 1 || g.isDone() {
+		return nil
+	}
+
+	saved := g.save()
+	pq := make(prioQueue, 0)
+	g.populateForTrial(&pq)
+	for pq.Len() > 0 && !g.isTimedOut() {
+		c := heap.Pop(&pq).(*PrioSquare)
+		// the square might have been found thanks to a previous candidate
+		if c.value != EMPTY || !c.canBe(c.pvalue) {
+			continue
+		}
+		err := g.tryValue(c.Square, c.pvalue, depth-1)
+		if err == nil && g.isDone() {
+			return nil
+		}
+		g.restore(saved)
+		if err != nil {
+			g.exclude(c.Square, c.pvalue)
+			if err := g.solveByTrial(); err != nil {
+				return err
+			}
+			if g.isDone() {
+				return nil
+			}
+			saved = g.save()
+		}
+	}
+	return nil
+}
+
+// setDeadline starts the time allowed by the TrialTimeout option, if any
+func (g *Griddler) setDeadline() {
+	g.deadline = time.Time{}
+	if g.opts.TrialTimeout > 0 {
+		g.deadline = time.Now().Add(g.opts.TrialTimeout)
+	}
+}
+
+// isTimedOut indicates if the time allowed to the trial&error phase is over
+func (g *Griddler) isTimedOut() bool {
+	return !g.deadline.IsZero() && time.Now().After(g.deadline)
+}
+
+func (g *Griddler) populateForTrial(pq *prioQueue) (selected int, potential int, total int) {
+	for _, l := range g.lines {
+		for _, s := range l.squares {
+			total++
+			if s.value == EMPTY {
+				potential++
+				// to assign a higher priority, we check for borders and neighbours
+				// along each line containing the square
+				priority := 0
+				pvalue := g.firstColor(s)
+				for _, ref := range g.linesOf(s) {
+					if ref.i == 0 || ref.i == ref.l.length-1 {
+						priority++
+					}
+					for _, n := range []int{ref.i - 1, ref.i + 1} {
+						if n >= 0 && n < ref.l.length && ref.l.squares[n].value != EMPTY {
+							if ref.l.squares[n].value == FILLED {
+								pvalue = backgroundColor
+							}
+							priority++
+						}
+					}
+				}
+				// the blank might have been excluded already on a colored griddler
+				if !s.canBe(pvalue) {
+					pvalue = g.firstColor(s)
+				}
+				// we only add those
+				if priority > 0 {
+					selected++
+					heap.Push(pq, &PrioSquare{s, pvalue, priority})
+				}
+			}
+		}
+	}
+	return
+}
+
+func (g *Griddler) solveByTrial() (err error) {
+	defer g.countContradiction(&err)
+	defer recoverSolveError(&err)
+	g.solveGeneric()
+	g.verify()
+	return
+}
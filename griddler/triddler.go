@@ -0,0 +1,140 @@
+package griddler
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// A triddler is a triangle of side N made of triangular squares, whose clues run
+// along three axes: the N rows (H), read from left to right, the diagonals
+// parallel to the left side (L), numbered from the left side and read from top
+// to bottom, and the diagonals parallel to the right side (R), numbered from the
+// right side and read from top to bottom. Its definition uses the native format
+// with the header "triddler N":
+//
+//	triddler 3
+//	H:1;1
+//	H:2;3
+//	...
+//	L:1;1,1
+//	...
+//	R:3;1
+//
+// The row r (0-based) holds 2r+1 squares, the even ones pointing up and the odd
+// ones pointing down, so the board of a triddler is stored as the triangular
+// part of a N x (2N-1) rectangle.
+const triddlerHeader = "triddler "
+
+var (
+	gridAxisTokens     = []string{"H", "V"}
+	gridAxisNames      = []string{"line", "column"}
+	triddlerAxisTokens = []string{"H", "L", "R"}
+	triddlerAxisNames  = []string{"row", "left diagonal", "right diagonal"}
+)
+
+// axisTokens returns the tokens identifying the axes in the definition
+func (g *Griddler) axisTokens() []string {
+	if g.isTriddler {
+		return triddlerAxisTokens
+	}
+	return gridAxisTokens
+}
+
+// axisNames returns the names of the line families, for display purposes
+func (g *Griddler) axisNames() []string {
+	if g.isTriddler {
+		return triddlerAxisNames
+	}
+	return gridAxisNames
+}
+
+// axisIndex returns the index of the axis identified by the token, -1 if unknown
+func (g *Griddler) axisIndex(token string) int {
+	for k, t := range g.axisTokens() {
+		if t == token {
+			return k
+		}
+	}
+	return -1
+}
+
+// setTriddler defines the griddler as a triddler of the given side and
+// initializes its board
+func (g *Griddler) setTriddler(side int) {
+	g.width = 2*side - 1
+	g.height = side
+	g.problems = nil
+	g.palette = defaultPalette()
+	g.isTriddler = true
+	g.stacks = make([]Stack, len(triddlerAxisTokens))
+
+	g.board = make([]Square, g.width*g.height)
+	for i := range g.board {
+		g.board[i] = Square{Tile: Tile{EMPTY}, x: i / g.width, y: i % g.width}
+	}
+	// the rows and both diagonals of index i have the same length
+	axes := make([][](*Line), len(triddlerAxisTokens))
+	for k := range axes {
+		axes[k] = make([](*Line), side)
+		for i := 0; i < side; i++ {
+			length := 2*i + 1
+			if k > 0 {
+				length = 2*(side-i) - 1
+			}
+			axes[k][i] = NewLine(g, i, length)
+		}
+	}
+	g.setAxes(axes)
+	for r := 0; r < side; r++ {
+		for k := 0; k <= 2*r; k++ {
+			s := g.square(r, k)
+			for _, ref := range g.linesOf(s) {
+				ref.l.squares[ref.i] = s
+			}
+		}
+	}
+}
+
+// triddlerLinesOf returns the row and the diagonals containing the square: the
+// square k of the row r belongs to the left diagonal k/2 and to the right
+// diagonal r-(k+1)/2, where it is found at the index k
+func (g *Griddler) triddlerLinesOf(s *Square) []lineRef {
+	r, k := s.x, s.y
+	left := k / 2
+	return []lineRef{
+		{g.axes[0][r], k},
+		{g.axes[1][left], 2*(r-left) - k%2},
+		{g.axes[2][r-(k+1)/2], k},
+	}
+}
+
+// printTriddler writes the rows of the triddler centered, followed by the
+// completed diagonals
+func (g *Griddler) printTriddler(w io.Writer) {
+	lw := labelWidth(g.height)
+	for i, l := range g.lines {
+		margin := strings.Repeat(" ", g.height-1-i)
+		fmt.Fprintf(w, "%*d |%s", lw, i+1, margin)
+		for _, s := range l.squares {
+			g.showSquare(w, s)
+		}
+		fmt.Fprintf(w, "%s| %-*d", margin, lw, i+1)
+		if l.isDone {
+			fmt.Fprintf(w, " D")
+		}
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintln(w)
+	for k, ls := range g.axes[1:] {
+		fmt.Fprintf(w, "%s: ", g.axisTokens()[k+1])
+		for _, l := range ls {
+			if l.isDone {
+				fmt.Fprintf(w, "D")
+			} else {
+				fmt.Fprintf(w, " ")
+			}
+		}
+		fmt.Fprintln(w)
+	}
+}
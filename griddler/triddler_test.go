@@ -0,0 +1,109 @@
+package griddler
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestTriddlerGeometry(t *testing.T) {
+	const side = 4
+	g := New(SolverOptions{})
+	if err := g.Parse([]byte("triddler 4\n")); err != nil {
+		t.Fatal(err)
+	}
+	for k, ls := range g.axes {
+		for i, l := range ls {
+			length := 2*i + 1
+			if k > 0 {
+				length = 2*(side-i) - 1
+			}
+			if len(l.squares) != length {
+				t.Errorf("%s:%d has %d squares, expected %d", g.axisTokens()[k], i+1, len(l.squares), length)
+			}
+		}
+	}
+
+	// every square of the triangle belongs to one line of each axis, at the
+	// index given by linesOf, the adjacent squares of a diagonal alternating
+	// their orientation as in a row
+	count := 0
+	for r := 0; r < side; r++ {
+		for k := 0; k <= 2*r; k++ {
+			count++
+			s := g.square(r, k)
+			refs := g.linesOf(s)
+			if len(refs) != len(g.axes) {
+				t.Fatalf("square %d,%d is in %d lines", r+1, k+1, len(refs))
+			}
+			for a, ref := range refs {
+				if ref.l.squares[ref.i] != s {
+					t.Errorf("square %d,%d is not at index %d of %s:%d", r+1, k+1, ref.i+1, g.axisTokens()[a], ref.l.index+1)
+				}
+				if ref.i%2 != k%2 {
+					t.Errorf("square %d,%d has the parity %d in %s:%d", r+1, k+1, ref.i%2, g.axisTokens()[a], ref.l.index+1)
+				}
+			}
+		}
+	}
+	for k, ls := range g.axes {
+		total := 0
+		for _, l := range ls {
+			total += len(l.squares)
+		}
+		if total != count {
+			t.Errorf("the axis %s covers %d squares, expected %d", g.axisTokens()[k], total, count)
+		}
+	}
+
+	// the diagonals being read from top to bottom, the apex starts the first ones
+	apex := g.square(0, 0)
+	if g.axes[1][0].squares[0] != apex || g.axes[2][0].squares[0] != apex {
+		t.Errorf("the apex is misplaced in the diagonals")
+	}
+}
+
+func TestTriddlerSolve(t *testing.T) {
+	// the clues are read from a picture drawn on the board
+	filled := map[[2]int]bool{
+		{0, 0}: true,
+		{1, 0}: true, {1, 2}: true,
+		{2, 1}: true, {2, 2}: true, {2, 3}: true,
+		{3, 0}: true, {3, 1}: true, {3, 5}: true, {3, 6}: true,
+	}
+	picture := New(SolverOptions{})
+	if err := picture.Parse([]byte("triddler 4\n")); err != nil {
+		t.Fatal(err)
+	}
+	for _, ls := range picture.axes {
+		for _, l := range ls {
+			cells := make([]bool, len(l.squares))
+			for i, s := range l.squares {
+				cells[i] = filled[[2]int{s.x, s.y}]
+			}
+			l.addClues(cluesOf(cells))
+		}
+	}
+	var def bytes.Buffer
+	if err := picture.Save(&def); err != nil {
+		t.Fatal(err)
+	}
+
+	g := New(SolverOptions{})
+	if err := g.Parse(def.Bytes()); err != nil {
+		t.Fatalf("%v\n%s", err, def.String())
+	}
+	res, err := g.Solve()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Solved {
+		t.Fatalf("not solved:\n%s", def.String())
+	}
+	for r, l := range g.lines {
+		for k, s := range l.squares {
+			if expected := filled[[2]int{r, k}]; (s.value == FILLED) != expected {
+				t.Errorf("square %d,%d is %d", r+1, k+1, s.value)
+			}
+		}
+	}
+}
@@ -1,10 +1,16 @@
 package griddler
 
 import (
+	"bufio"
 	"fmt"
+	"io"
 	"os"
 )
 
+// maxLineLength is the longest line accepted when reading a definition, the
+// clues of large grids going far beyond the default limit of bufio.Scanner
+const maxLineLength = 16 * 1024 * 1024
+
 // utility function to pause and wait for the user to press enter
 func PauseEnter() {
 	fmt.Println("Press Enter to continue...")
@@ -12,6 +18,13 @@ func PauseEnter() {
 	os.Stdin.Read(b)
 }
 
+// newScanner returns a line scanner on r accepting lines up to maxLineLength
+func newScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxLineLength)
+	return scanner
+}
+
 func max(a, b int) int {
 	if a < b {
 		return b
@@ -35,27 +48,24 @@ func IncOrDec(i int, reverse bool) int {
 	return i
 }
 
-// Special stack implementation where elements are unique
-type Element struct {
-	Value interface{}
-}
-
-type Stack []Element
+// Special stack implementation where elements are unique, each line knowing
+// if it is already stacked to keep push constant in time on large grids
+type Stack [](*Line)
 
-func (st *Stack) push(elt interface{}) {
-	for _, ste := range *st {
-		if ste.Value == elt {
-			return
-		}
+func (st *Stack) push(l *Line) {
+	if l.isStacked {
+		return
 	}
-	*st = append(*st, Element{Value: elt})
+	l.isStacked = true
+	*st = append(*st, l)
 }
 
-func (st *Stack) pop() interface{} {
+func (st *Stack) pop() *Line {
 	if len(*st) == 0 {
 		return nil
 	}
 	ret := (*st)[len(*st)-1]
 	*st = (*st)[0 : len(*st)-1]
-	return ret.Value
+	ret.isStacked = false
+	return ret
 }
@@ -0,0 +1,81 @@
+package griddler
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Validate checks the griddler definition before solving and reports every
+// problem found as ParseErrors: the ones met while loading (invalid or duplicate
+// line info, indexes out of range) as well as the invalid clue values, the lines
+// whose clues can not fit and a difference between the line and column totals.
+func (g *Griddler) Validate() error {
+	problems := append(ParseErrors{}, g.problems...)
+
+	// the totals are compared color by color, as ranges when some clues are
+	// unknown
+	total := make([]map[int]Range, len(g.axes))
+	for k, ls := range g.axes {
+		total[k] = map[int]Range{}
+		for _, l := range ls {
+			if err := l.validate(); err != nil {
+				problems = append(problems, &ParseError{
+					line: l.src,
+					err:  fmt.Errorf("%s %d: %w", l.kind(), l.index+1, err),
+				})
+			}
+			for _, c := range l.clues {
+				t := total[k][c.color]
+				total[k][c.color] = Range{t.min + c.length, t.max + c.maxLen}
+			}
+			if l.isHidden() {
+				for color := defaultColor; color < len(g.palette); color++ {
+					t := total[k][color]
+					total[k][color] = Range{t.min, t.max + l.length}
+				}
+			}
+		}
+	}
+	for _, t := range total[1:] {
+		if !matchingTotals(total[0], t) {
+			problems = append(problems, &ParseError{err: ErrTotalsMismatch})
+			break
+		}
+	}
+
+	if len(problems) > 0 {
+		sort.SliceStable(problems, func(i, j int) bool {
+			return problems[i].line < problems[j].line
+		})
+		return problems
+	}
+	return nil
+}
+
+// validate checks that the clues of the line are positive and can fit in it
+func (l *Line) validate() error {
+	for _, c := range l.clues {
+		if c.length < 0 || (c.length == 0 && len(l.clues) > 1) {
+			return ErrInvalidClueValue
+		}
+	}
+	if l.minSize() > l.length {
+		return ErrCluesTooLong
+	}
+	return nil
+}
+
+// matchingTotals indicates if the totals of two axes can be equal for every color
+func matchingTotals(a, b map[int]Range) bool {
+	for color := range b {
+		if a[color].min > b[color].max || b[color].min > a[color].max {
+			return false
+		}
+	}
+	for color := range a {
+		if a[color].min > b[color].max || b[color].min > a[color].max {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,239 @@
+package griddler
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+// xml mapping of the webpbn.com export format
+type pbnPuzzleSet struct {
+	Puzzles []pbnPuzzle `xml:"puzzle"`
+}
+
+type pbnPuzzle struct {
+	Type            string        `xml:"type,attr"`
+	DefaultColor    string        `xml:"defaultcolor,attr"`
+	BackgroundColor string        `xml:"backgroundcolor,attr"`
+	Colors          []pbnColor    `xml:"color"`
+	Clues           []pbnClues    `xml:"clues"`
+	Solutions       []pbnSolution `xml:"solution"`
+}
+
+type pbnColor struct {
+	Name  string `xml:"name,attr"`
+	Char  string `xml:"char,attr"`
+	Value string `xml:",chardata"`
+}
+
+type pbnClues struct {
+	Type  string    `xml:"type,attr"`
+	Lines []pbnLine `xml:"line"`
+}
+
+type pbnLine struct {
+	Counts []pbnCount `xml:"count"`
+}
+
+type pbnCount struct {
+	Color  string `xml:"color,attr"`
+	Length int    `xml:",chardata"`
+}
+
+type pbnSolution struct {
+	Type  string `xml:"type,attr"`
+	Image string `xml:"image"`
+}
+
+// LoadWebpbn reads a puzzle in the webpbn.com XML format, the first puzzle of a
+// puzzle set being used, its colors being added to the palette. Its goal
+// solution, if present, is available with Goal().
+func (g *Griddler) LoadWebpbn(r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	// the root element is either a puzzle set or a single puzzle
+	var set pbnPuzzleSet
+	if err := xml.Unmarshal(data, &set); err != nil {
+		return err
+	}
+	if len(set.Puzzles) == 0 {
+		var p pbnPuzzle
+		if err := xml.Unmarshal(data, &p); err != nil {
+			return err
+		}
+		set.Puzzles = append(set.Puzzles, p)
+	}
+	p := set.Puzzles[0]
+	if p.Type != "" && p.Type != "grid" {
+		return ErrUnsupportedPuzzle
+	}
+
+	var rows, columns []pbnLine
+	for _, c := range p.Clues {
+		switch c.Type {
+		case "rows":
+			rows = c.Lines
+		case "columns":
+			columns = c.Lines
+		}
+	}
+	if len(rows) == 0 || len(columns) == 0 {
+		return ErrMissingClues
+	}
+
+	g.setSize(len(columns), len(rows))
+	colors, err := p.addColors(g)
+	if err != nil {
+		return err
+	}
+	for i, row := range rows {
+		cs, err := p.clueList(row, colors)
+		if err != nil {
+			return err
+		}
+		g.lines[i].addClues(cs)
+	}
+	for i, col := range columns {
+		cs, err := p.clueList(col, colors)
+		if err != nil {
+			return err
+		}
+		g.columns[i].addClues(cs)
+	}
+
+	for _, s := range p.Solutions {
+		if s.Type == "" || s.Type == "goal" || s.Type == "solution" {
+			g.goal, err = p.parseImage(s.Image, g.width, g.height, colors)
+			if err != nil {
+				return err
+			}
+			break
+		}
+	}
+	return nil
+}
+
+// addColors fills the palette of the griddler with the colors of the puzzle and
+// returns the palette index of each color name. A color is named in the palette
+// by its char when it is a valid color name, by the first letter available
+// otherwise.
+func (p *pbnPuzzle) addColors(g *Griddler) (map[string]int, error) {
+	colors := map[string]int{p.defaultColor(): defaultColor}
+	colors[p.backgroundColor()] = backgroundColor
+	for _, c := range p.Colors {
+		rgb, err := parseRGB(c.Value)
+		if err != nil {
+			return nil, err
+		}
+		if index, ok := colors[c.Name]; ok {
+			g.palette[index].RGB = rgb
+			continue
+		}
+		name := c.Char
+		if !isColorName(name) || g.colorIndex(name) >= 0 {
+			name = g.freeColorName()
+		}
+		index, err := g.AddColor(name, rgb)
+		if err != nil {
+			return nil, err
+		}
+		colors[c.Name] = index
+	}
+	return colors, nil
+}
+
+// freeColorName returns the first letter not used yet as a color name
+func (g *Griddler) freeColorName() string {
+	for _, letters := range []string{"abcdefghijklmnopqrstuvwxyz", "ABCDEFGHIJKLMNOPQRSTUVWXYZ"} {
+		for i := range letters {
+			if isColorName(letters[i:i+1]) && g.colorIndex(letters[i:i+1]) < 0 {
+				return letters[i : i+1]
+			}
+		}
+	}
+	return ""
+}
+
+func (p *pbnPuzzle) clueList(l pbnLine, colors map[string]int) ([](*Clue), error) {
+	cs := make([](*Clue), len(l.Counts))
+	for i, n := range l.Counts {
+		name := n.Color
+		if name == "" {
+			name = p.defaultColor()
+		}
+		color, ok := colors[name]
+		if !ok || color == backgroundColor {
+			return nil, ErrUnknownColor
+		}
+		cs[i] = NewClue(n.Length)
+		cs[i].color = color
+	}
+	return cs, nil
+}
+
+// parseImage reads a solution image where each line is delimited by '|' and
+// each square is the char of its color, '.' being the blank squares when not
+// used by a color
+func (p *pbnPuzzle) parseImage(image string, width, height int, colors map[string]int) (*Solution, error) {
+	chars := map[rune]int{}
+	for _, c := range p.Colors {
+		for _, ch := range c.Char {
+			chars[ch] = colors[c.Name]
+		}
+	}
+	if _, ok := chars['.']; !ok {
+		chars['.'] = backgroundColor
+	}
+
+	s := newSolution(width, height)
+	i := 0
+	for _, row := range strings.Split(image, "\n") {
+		row = strings.Trim(strings.TrimSpace(row), "|")
+		if row == "" {
+			continue
+		}
+		if i >= height || len(row) != width {
+			return nil, ErrInvalidSolutionImage
+		}
+		for j, ch := range row {
+			color, ok := chars[ch]
+			switch {
+			case ok:
+				s.set(i, j, color)
+			case len(p.Colors) == 0:
+				s.set(i, j, defaultColor)
+			default:
+				return nil, ErrInvalidSolutionImage
+			}
+		}
+		i++
+	}
+	if i != height {
+		return nil, ErrInvalidSolutionImage
+	}
+	return s, nil
+}
+
+// defaultColor returns the name of the color of the counts without color
+func (p *pbnPuzzle) defaultColor() string {
+	if p.DefaultColor == "" {
+		return "black"
+	}
+	return p.DefaultColor
+}
+
+// backgroundColor returns the name of the color of the blank squares
+func (p *pbnPuzzle) backgroundColor() string {
+	if p.BackgroundColor == "" {
+		return "white"
+	}
+	return p.BackgroundColor
+}
+
+// Goal returns the solution provided with the puzzle definition, if any
+func (g *Griddler) Goal() *Solution {
+	return g.goal
+}
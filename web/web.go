@@ -3,11 +3,11 @@ package main
 import (
 	"crypto/md5"
 	"fmt"
+	"github.com/MeTaNoV/gogrid/griddler"
 	"html/template"
 	"io"
 	"log"
 	"net/http"
-	"os"
 	"strconv"
 	"strings"
 	"time"
@@ -70,14 +70,15 @@ func upload(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		defer file.Close()
-		fmt.Fprintf(w, "%v", handler.Header)
-		f, err := os.OpenFile("./test/"+handler.Filename, os.O_WRONLY|os.O_CREATE, 0666)
+		fmt.Fprintf(w, "%v\n", handler.Header)
+		// the griddler is parsed directly from the uploaded content
+		gBoard := griddler.New(griddler.SolverOptions{})
+		err = gBoard.LoadFrom(file)
 		if err != nil {
-			fmt.Println(err)
+			fmt.Fprintf(w, "Error loading %s: %v\n", handler.Filename, err)
 			return
 		}
-		defer f.Close()
-		io.Copy(f, file)
+		fmt.Fprintf(w, "Griddler %s loaded\n", handler.Filename)
 	}
 }
 